@@ -0,0 +1,79 @@
+package adapterstest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// MockResponse describes the canned result which a MockTransport should replay for a single URL.
+//
+// If Err is set, the round trip fails with that error, simulating a connection problem.
+// If Delay is set, the transport waits that long before responding. If the request's context
+// expires first, the round trip fails with the context's error, simulating a timeout.
+type MockResponse struct {
+	StatusCode int
+	Body       string
+	Headers    http.Header
+	Delay      time.Duration
+	Err        error
+}
+
+// MockTransport is an http.RoundTripper which replays canned responses keyed by the request URL.
+// It lets Bidder tests exercise the full HTTP flow without standing up an httptest.Server.
+//
+// Requests to URLs with no entry in Responses fail with an error.
+type MockTransport struct {
+	Responses map[string]MockResponse
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	mockResponse, ok := t.Responses[req.URL.String()]
+	if !ok {
+		return nil, fmt.Errorf("no mock response defined for %s", req.URL.String())
+	}
+
+	if mockResponse.Delay > 0 {
+		select {
+		case <-time.After(mockResponse.Delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if mockResponse.Err != nil {
+		return nil, mockResponse.Err
+	}
+
+	statusCode := mockResponse.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	headers := mockResponse.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(mockResponse.Body)),
+		ContentLength: int64(len(mockResponse.Body)),
+		Request:       req,
+	}, nil
+}
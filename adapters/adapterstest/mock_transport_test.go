@@ -0,0 +1,73 @@
+package adapterstest
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockTransportUnknownURL(t *testing.T) {
+	transport := &MockTransport{}
+	req, _ := http.NewRequest("GET", "http://bidder.com/unknown", nil)
+
+	resp, err := transport.RoundTrip(req)
+	assert.EqualError(t, err, "no mock response defined for http://bidder.com/unknown")
+	assert.Nil(t, resp)
+}
+
+func TestMockTransportDefaults(t *testing.T) {
+	transport := &MockTransport{
+		Responses: map[string]MockResponse{
+			"http://bidder.com/bid": {
+				Body: `{"id":"some-id"}`,
+			},
+		},
+	}
+	req, _ := http.NewRequest("POST", "http://bidder.com/bid", nil)
+
+	resp, err := transport.RoundTrip(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "The status should default to 200.")
+	assert.NotNil(t, resp.Header, "Headers should never be nil.")
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, `{"id":"some-id"}`, string(body))
+}
+
+func TestMockTransportErrorAfterDelay(t *testing.T) {
+	connectionErr := errors.New("connection reset")
+	transport := &MockTransport{
+		Responses: map[string]MockResponse{
+			"http://bidder.com/bid": {
+				Delay: time.Millisecond,
+				Err:   connectionErr,
+			},
+		},
+	}
+	req, _ := http.NewRequest("POST", "http://bidder.com/bid", nil)
+
+	resp, err := transport.RoundTrip(req)
+	assert.Equal(t, connectionErr, err, "Err should be returned once the delay has passed.")
+	assert.Nil(t, resp)
+}
+
+func TestMockTransportCancelledContext(t *testing.T) {
+	transport := &MockTransport{
+		Responses: map[string]MockResponse{
+			"http://bidder.com/bid": {},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequest("POST", "http://bidder.com/bid", nil)
+
+	resp, err := transport.RoundTrip(req.WithContext(ctx))
+	assert.Equal(t, context.Canceled, err, "A request whose context is already done should not get a response.")
+	assert.Nil(t, resp)
+}
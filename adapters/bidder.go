@@ -52,6 +52,19 @@ type TimeoutBidder interface {
 	MakeTimeoutNotification(req *RequestData) (*RequestData, []error)
 }
 
+// WinNotifier is used to identify bidders that want to be notified by Prebid Server when one of their bids wins an auction.
+// This is separate from any win notice URLs in the bid itself, which the ad server or client is responsible for.
+type WinNotifier interface {
+	Bidder
+
+	// MakeWinNotification is fed the bid which won an imp's auction, and expects that only one notification "request"
+	// will be generated. The bid's price has already been adjusted and converted into the auction currency.
+	// The bid's Seat is empty if it was bid on behalf of the Bidder itself.
+	//
+	// If the Bidder doesn't need to be notified about this particular bid, it should return nil and no errors.
+	MakeWinNotification(bid *TypedBid) (*RequestData, []error)
+}
+
 type MisconfiguredBidder struct {
 	Name  string
 	Error error
@@ -71,6 +84,14 @@ func BadInput(msg string) *errortypes.BadInput {
 	}
 }
 
+// MalformedResponse should be used by Bidders which can't parse the body of their server's response.
+func MalformedResponse(msg string) *errortypes.BadServerResponse {
+	return &errortypes.BadServerResponse{
+		Message: msg,
+		Subcode: errortypes.MalformedResponseSubcode,
+	}
+}
+
 // BidderResponse wraps the server's response with the list of bids and the currency used by the bidder.
 //
 // Currency declaration is not mandatory but helps to detect an eventual currency mismatch issue.
@@ -102,6 +123,18 @@ func NewBidderResponse() *BidderResponse {
 	return NewBidderResponseWithBidsCapacity(0)
 }
 
+// MediaTypeAwareBidder may be implemented by Bidders which know, in code, which media types they can bid on.
+//
+// EnforceBidderInfo removes any other media types from the imps before MakeRequests is called, so these
+// Bidders don't need to check for unsupported imps themselves. Bidders which don't implement it are only
+// filtered by their static/bidder-info/{bidder}.yaml file.
+type MediaTypeAwareBidder interface {
+	Bidder
+
+	// SupportedMediaTypes returns the media types which this Bidder can bid on.
+	SupportedMediaTypes() map[openrtb_ext.BidType]bool
+}
+
 // TypedBid packages the openrtb.Bid with any bidder-specific information that PBS needs to populate an
 // openrtb_ext.ExtBidPrebid.
 //
@@ -109,11 +142,17 @@ func NewBidderResponse() *BidderResponse {
 // TypedBid.BidType will become "response.seatbid[i].bid.ext.prebid.type" in the final OpenRTB response.
 // TypedBid.BidVideo will become "response.seatbid[i].bid.ext.prebid.video" in the final OpenRTB response.
 // TypedBid.DealPriority will become "response.seatbid[i].bid.dealPriority" in the final OpenRTB response.
+// TypedBid.Seat is optional. If set, it will become "response.seatbid[i].seat" in the final OpenRTB response.
+// Bidders should use it to preserve the seatbid.seat from their own responses. If empty, the Bidder's name is used.
+// TypedBid.Currency is optional. Bidders which aggregate buyers paying in different currencies should set it to
+// the currency of Bid.Price. If empty, BidderResponse.Currency is used.
 type TypedBid struct {
 	Bid          *openrtb.Bid
 	BidType      openrtb_ext.BidType
 	BidVideo     *openrtb_ext.ExtBidPrebidVideo
 	DealPriority int
+	Seat         string
+	Currency     string
 }
 
 // RequestData and ResponseData exist so that prebid-server core code can implement its "debug" functionality
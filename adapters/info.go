@@ -23,10 +23,19 @@ import (
 //      to nil before the request is forwarded to the delegate.
 //   3. Any Imps which have no MediaTypes left will be removed.
 //   4. If there are no valid Imps left, the delegate won't be called at all.
+//
+// If the Bidder implements MediaTypeAwareBidder, only the MediaTypes supported by both
+// the info file and the Bidder itself are forwarded.
 func EnforceBidderInfo(bidder Bidder, info BidderInfo) Bidder {
+	parsedInfo := parseBidderInfo(info)
+	if mediaTypeBidder, ok := bidder.(MediaTypeAwareBidder); ok {
+		supported := mediaTypeBidder.SupportedMediaTypes()
+		parsedInfo.app = parsedInfo.app.restrictTo(supported)
+		parsedInfo.site = parsedInfo.site.restrictTo(supported)
+	}
 	return &InfoAwareBidder{
 		Bidder: bidder,
-		info:   parseBidderInfo(info),
+		info:   parsedInfo,
 	}
 }
 
@@ -60,6 +69,9 @@ func (i *InfoAwareBidder) MakeRequests(request *openrtb.BidRequest, reqInfo *Ext
 		filteredImps, newErrs := i.filterImps(request.Imp, numToFilter)
 		request.Imp = filteredImps
 		errs = append(errs, newErrs...)
+		if len(request.Imp) == 0 {
+			return nil, errs
+		}
 	}
 	reqs, delegateErrs := i.Bidder.MakeRequests(request, reqInfo)
 	return reqs, append(errs, delegateErrs...)
@@ -235,6 +247,15 @@ type parsedSupports struct {
 	native  bool
 }
 
+// restrictTo drops support for any MediaTypes which aren't in the given set.
+func (s parsedSupports) restrictTo(supported map[openrtb_ext.BidType]bool) parsedSupports {
+	s.banner = s.banner && supported[openrtb_ext.BidTypeBanner]
+	s.video = s.video && supported[openrtb_ext.BidTypeVideo]
+	s.audio = s.audio && supported[openrtb_ext.BidTypeAudio]
+	s.native = s.native && supported[openrtb_ext.BidTypeNative]
+	return s
+}
+
 func parseBidderInfo(info BidderInfo) parsedBidderInfo {
 	var parsedInfo parsedBidderInfo
 	if info.Capabilities.App != nil {
@@ -113,6 +113,85 @@ func TestImpFiltering(t *testing.T) {
 	assert.Nil(t, req.Imp[1].Native)
 }
 
+func TestImpFilteringByDeclaredMediaTypes(t *testing.T) {
+	bidder := &mediaTypeAwareBidder{
+		supported: map[openrtb_ext.BidType]bool{openrtb_ext.BidTypeBanner: true},
+	}
+	info := adapters.BidderInfo{
+		Capabilities: &adapters.CapabilitiesInfo{
+			Site: &adapters.PlatformInfo{
+				MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeVideo},
+			},
+		},
+	}
+
+	constrained := adapters.EnforceBidderInfo(bidder, info)
+	_, errs := constrained.MakeRequests(&openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{
+				ID:     "imp-1",
+				Banner: &openrtb.Banner{},
+				Video:  &openrtb.Video{},
+			},
+			{
+				ID:    "imp-2",
+				Video: &openrtb.Video{},
+			},
+		},
+		Site: &openrtb.Site{},
+	}, &adapters.ExtraRequestInfo{})
+	if !assert.Len(t, errs, 4) {
+		return
+	}
+	assert.EqualError(t, errs[0], "request.imp[0] uses video, but this bidder doesn't support it")
+	assert.EqualError(t, errs[1], "request.imp[1] uses video, but this bidder doesn't support it")
+	assert.EqualError(t, errs[2], "request.imp[1] has no supported MediaTypes. It will be ignored")
+	assert.EqualError(t, errs[3], "mock MakeRequests error")
+
+	req := bidder.gotRequest
+	if !assert.Len(t, req.Imp, 1) {
+		return
+	}
+	assert.Equal(t, "imp-1", req.Imp[0].ID)
+	assert.Nil(t, req.Imp[0].Video)
+}
+
+func TestNoImpsLeft(t *testing.T) {
+	bidder := &mediaTypeAwareBidder{
+		supported: map[openrtb_ext.BidType]bool{openrtb_ext.BidTypeBanner: true},
+	}
+	info := adapters.BidderInfo{
+		Capabilities: &adapters.CapabilitiesInfo{
+			Site: &adapters.PlatformInfo{
+				MediaTypes: []openrtb_ext.BidType{openrtb_ext.BidTypeBanner, openrtb_ext.BidTypeVideo},
+			},
+		},
+	}
+
+	constrained := adapters.EnforceBidderInfo(bidder, info)
+	reqs, errs := constrained.MakeRequests(&openrtb.BidRequest{
+		Imp: []openrtb.Imp{{
+			ID:    "imp-1",
+			Video: &openrtb.Video{},
+		}},
+		Site: &openrtb.Site{},
+	}, &adapters.ExtraRequestInfo{})
+	assert.Len(t, reqs, 0)
+	if assert.Len(t, errs, 2) {
+		assert.EqualError(t, errs[1], "request.imp[0] has no supported MediaTypes. It will be ignored")
+	}
+	assert.Nil(t, bidder.gotRequest, "The bidder should not be called if no imps are left.")
+}
+
+type mediaTypeAwareBidder struct {
+	mockBidder
+	supported map[openrtb_ext.BidType]bool
+}
+
+func (m *mediaTypeAwareBidder) SupportedMediaTypes() map[openrtb_ext.BidType]bool {
+	return m.supported
+}
+
 type mockBidder struct {
 	gotRequest *openrtb.BidRequest
 }
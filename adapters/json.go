@@ -0,0 +1,16 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// DecodeJSON unmarshals data into v, decoding numbers as json.Number rather than float64.
+//
+// Bidders whose responses carry prices in scientific notation or with more precision than a float64 holds
+// can unmarshal those fields into json.Number (or interface{}) and convert them once they know what they need.
+func DecodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,29 @@
+package adapters
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeJSONKeepsNumbers(t *testing.T) {
+	var parsed struct {
+		Price json.Number `json:"price"`
+		Ext   interface{} `json:"ext"`
+	}
+	err := DecodeJSON([]byte(`{"price":1.2345678901234567890e-1,"ext":{"floor":0.30000000000000000001}}`), &parsed)
+
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("1.2345678901234567890e-1"), parsed.Price)
+	assert.Equal(t, map[string]interface{}{"floor": json.Number("0.30000000000000000001")}, parsed.Ext, "Untyped numbers should be decoded as json.Number.")
+
+	price, err := parsed.Price.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.12345678901234568, price)
+}
+
+func TestDecodeJSONBadInput(t *testing.T) {
+	var parsed map[string]interface{}
+	assert.Error(t, DecodeJSON([]byte(`{"price":`), &parsed))
+}
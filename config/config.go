@@ -13,6 +13,7 @@ import (
 	"github.com/prebid/prebid-server/macros"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/spf13/viper"
+	"golang.org/x/text/currency"
 
 	validator "github.com/asaskevich/govalidator"
 )
@@ -66,6 +67,37 @@ type Configuration struct {
 	PemCertsFile string `mapstructure:"certificates_file"`
 	// Custom headers to handle request timeouts from queueing infrastructure
 	RequestTimeoutHeaders RequestTimeoutHeaders `mapstructure:"request_timeout_headers"`
+	// DealTargetingKeyPrefix is the targeting key used to expose a winning bid's deal ID to the ad server.
+	// The bidder-specific key is built by appending "_{bidder}" to it. Since targeting keys are truncated
+	// to 20 characters, it may be no longer than the default "hb_deal".
+	DealTargetingKeyPrefix string `mapstructure:"deal_targeting_key_prefix"`
+	// CircuitBreaker stops calling bidders whose endpoints keep failing.
+	CircuitBreaker CircuitBreaker `mapstructure:"circuit_breaker"`
+	// BidLimits caps how many bids a single bidder may enter into each auction.
+	BidLimits BidLimits `mapstructure:"bid_limits"`
+	// Debug configures the debug output of test requests.
+	Debug Debug `mapstructure:"debug"`
+	// RequestCompression decides which requests are gzipped for the bidders which accept gzipped bodies.
+	RequestCompression RequestCompression `mapstructure:"request_compression"`
+	// MaxConcurrentBidderCalls bounds how many HTTP calls to bidders a single auction may have in flight
+	// at once, across all of its bidders. Use 0 for no limit.
+	MaxConcurrentBidderCalls int `mapstructure:"max_concurrent_bidder_calls"`
+	// BidExpiration normalizes the exp of each bid, so that bidders can't ask for creatives to be cached
+	// for absurdly long.
+	BidExpiration BidExpirations `mapstructure:"bid_expiration"`
+	// ADomainFilter screens bids by their advertiser domains. Requests can tighten it through
+	// request.ext.prebid.adomainfilter.
+	ADomainFilter ADomainFilter `mapstructure:"adomain_filter"`
+	// EnableChaosTesting allows the adapter settings which deliberately degrade bidder calls, like synthetic_latency_ms.
+	// They exist to exercise timeout handling in staging. Never enable it in production.
+	EnableChaosTesting bool `mapstructure:"enable_chaos_testing"`
+	// RequestIDHeader names a header which carries request.id on every call to a bidder, so that our logs can be
+	// matched up with theirs. Bidders which set the header themselves keep their own value. Leave it empty to send nothing.
+	RequestIDHeader string `mapstructure:"request_id_header"`
+	// BidderRequestHeaders are added to every call to a bidder, for things like tracing. The values may contain
+	// ${REQUEST_ID} and ${BIDDER}, which are replaced by request.id and the bidder's name. Bidders which set one
+	// of these headers themselves keep their own value.
+	BidderRequestHeaders map[string]string `mapstructure:"bidder_request_headers"`
 }
 
 const MIN_COOKIE_SIZE_BYTES = 500
@@ -74,6 +106,25 @@ type HTTPClient struct {
 	MaxIdleConns        int `mapstructure:"max_idle_connections"`
 	MaxIdleConnsPerHost int `mapstructure:"max_idle_connections_per_host"`
 	IdleConnTimeout     int `mapstructure:"idle_connection_timeout_seconds"`
+
+	// These bound each phase of a call on its own, so that a hung connection fails fast instead of using up
+	// the whole request deadline. 0 disables a timeout.
+	//
+	// ConnectTimeoutMs is the net.Dialer Timeout used by the transport's DialContext. It covers the DNS lookup and TCP connect.
+	ConnectTimeoutMs int `mapstructure:"connect_timeout_ms"`
+	// TLSHandshakeTimeoutMs is the transport's TLSHandshakeTimeout.
+	TLSHandshakeTimeoutMs int `mapstructure:"tls_handshake_timeout_ms"`
+	// ResponseHeaderTimeoutMs is the transport's ResponseHeaderTimeout. It starts once the request has been
+	// written, and ends when the response headers arrive. Reading the body is only bounded by the context.
+	ResponseHeaderTimeoutMs int `mapstructure:"response_header_timeout_ms"`
+}
+
+func (cfg *HTTPClient) validate(prefix string, errs configErrors) configErrors {
+	if cfg.MaxIdleConns < 0 || cfg.MaxIdleConnsPerHost < 0 || cfg.IdleConnTimeout < 0 ||
+		cfg.ConnectTimeoutMs < 0 || cfg.TLSHandshakeTimeoutMs < 0 || cfg.ResponseHeaderTimeoutMs < 0 {
+		errs = append(errs, fmt.Errorf("%s settings must be >= 0", prefix))
+	}
+	return errs
 }
 
 type configErrors []error
@@ -101,9 +152,52 @@ func (cfg *Configuration) validate() configErrors {
 	if cfg.MaxRequestSize < 0 {
 		errs = append(errs, fmt.Errorf("cfg.max_request_size must be >= 0. Got %d", cfg.MaxRequestSize))
 	}
+	errs = cfg.Client.validate("http_client", errs)
+	errs = cfg.CacheClient.validate("http_client_cache", errs)
 	errs = cfg.GDPR.validate(errs)
 	errs = cfg.CurrencyConverter.validate(errs)
 	errs = validateAdapters(cfg.Adapters, errs)
+	if !cfg.EnableChaosTesting {
+		for adapterName, adapter := range cfg.Adapters {
+			if adapter.SyntheticLatencyMs != 0 {
+				errs = append(errs, fmt.Errorf("adapters.%s.synthetic_latency_ms is only allowed if enable_chaos_testing is true", adapterName))
+			}
+		}
+	}
+	errs = validateDealTargetingKeyPrefix(cfg.DealTargetingKeyPrefix, errs)
+	errs = validateBidderRequestHeaders(cfg.BidderRequestHeaders, errs)
+	errs = cfg.CircuitBreaker.validate(errs)
+	errs = cfg.BidLimits.validate(errs)
+	errs = cfg.RequestCompression.validate(errs)
+	errs = cfg.Debug.validate(errs)
+	errs = cfg.BidExpiration.validate(errs)
+	errs = cfg.ADomainFilter.validate(errs)
+	if cfg.MaxConcurrentBidderCalls < 0 {
+		errs = append(errs, fmt.Errorf("max_concurrent_bidder_calls must be >= 0. Got %d", cfg.MaxConcurrentBidderCalls))
+	}
+	return errs
+}
+
+// maxDealTargetingKeyPrefixLength matches the length of the default "hb_deal" prefix. Bidder-specific targeting
+// keys are truncated to 20 characters, so anything longer would cut into the bidder names and could make
+// the keys for different bidders collide.
+const maxDealTargetingKeyPrefixLength = 7
+
+func validateDealTargetingKeyPrefix(prefix string, errs configErrors) configErrors {
+	if prefix == "" || len(prefix) > maxDealTargetingKeyPrefixLength {
+		errs = append(errs, fmt.Errorf("deal_targeting_key_prefix must be between 1 and %d characters long. Got \"%s\"", maxDealTargetingKeyPrefixLength, prefix))
+	}
+	return errs
+}
+
+func validateBidderRequestHeaders(headers map[string]string, errs configErrors) configErrors {
+	for name, value := range headers {
+		if name == "" || strings.ContainsAny(name, " \t\r\n:") {
+			errs = append(errs, fmt.Errorf("bidder_request_headers must only contain valid header names. Got %q", name))
+		} else if strings.ContainsAny(value, "\r\n") {
+			errs = append(errs, fmt.Errorf("bidder_request_headers.%s must not contain line breaks", name))
+		}
+	}
 	return errs
 }
 
@@ -194,15 +288,32 @@ type Analytics struct {
 	File FileLogs `mapstructure:"file"`
 }
 
+// maxPriceDecimals is about as many decimal places as a float64 price can hold.
+const maxPriceDecimals = 10
+
 type CurrencyConverter struct {
 	FetchURL             string `mapstructure:"fetch_url"`
 	FetchIntervalSeconds int    `mapstructure:"fetch_interval_seconds"`
+	// PriceDecimals rounds bid prices to this many decimal places once they've been adjusted and converted,
+	// for ad servers which reject prices like 1.2300000000001. Use 0 to leave prices unrounded.
+	PriceDecimals int `mapstructure:"price_decimals"`
+	// FallbackCurrency is used for the bids of a Bidder when they can't be converted to any currency in request.cur.
+	// Use "" to reject those bids instead.
+	FallbackCurrency string `mapstructure:"fallback_currency"`
 }
 
 func (cfg *CurrencyConverter) validate(errs configErrors) configErrors {
 	if cfg.FetchIntervalSeconds < 0 {
 		errs = append(errs, fmt.Errorf("currency_converter.fetch_interval_seconds must be in the range [0, %d]. Got %d", 0xffff, cfg.FetchIntervalSeconds))
 	}
+	if cfg.PriceDecimals < 0 || cfg.PriceDecimals > maxPriceDecimals {
+		errs = append(errs, fmt.Errorf("currency_converter.price_decimals must be in the range [0, %d]. Got %d", maxPriceDecimals, cfg.PriceDecimals))
+	}
+	if cfg.FallbackCurrency != "" {
+		if _, err := currency.ParseISO(cfg.FallbackCurrency); err != nil {
+			errs = append(errs, fmt.Errorf("currency_converter.fallback_currency must be an ISO 4217 currency code. Got %q", cfg.FallbackCurrency))
+		}
+	}
 	return errs
 }
 
@@ -223,6 +334,145 @@ type HostCookie struct {
 	TTL int64 `mapstructure:"ttl_days"`
 }
 
+// CircuitBreaker configures when a bidder is temporarily skipped because its endpoint keeps failing.
+// The breaker state is tracked separately for each bidder, and shared by all auctions.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed HTTP calls which open the breaker. Use 0 to disable it.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// WindowMillis is the longest time which may pass between the first and last of those failures.
+	WindowMillis int `mapstructure:"window_ms"`
+	// CooldownMillis is how long calls to the bidder are skipped once the breaker opens.
+	CooldownMillis int `mapstructure:"cooldown_ms"`
+}
+
+func (cfg *CircuitBreaker) validate(errs configErrors) configErrors {
+	if cfg.FailureThreshold < 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.failure_threshold must be >= 0. Got %d", cfg.FailureThreshold))
+	}
+	if cfg.FailureThreshold > 0 && cfg.WindowMillis <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.window_ms must be positive if circuit_breaker.failure_threshold is defined. Got %d", cfg.WindowMillis))
+	}
+	if cfg.FailureThreshold > 0 && cfg.CooldownMillis <= 0 {
+		errs = append(errs, fmt.Errorf("circuit_breaker.cooldown_ms must be positive if circuit_breaker.failure_threshold is defined. Got %d", cfg.CooldownMillis))
+	}
+	return errs
+}
+
+// BidLimits protects the auction from bidders which return far more bids than they could ever win with.
+// When a bidder exceeds a limit, its highest-priced bids are kept. Use 0 for no limit.
+type BidLimits struct {
+	MaxBidsPerSeat int `mapstructure:"max_bids_per_seat"`
+	MaxBidsPerImp  int `mapstructure:"max_bids_per_imp"`
+	// MaxMarkupBytes drops bids whose adm is longer than this. Huge creatives bloat the response and can fail to cache.
+	MaxMarkupBytes int `mapstructure:"max_markup_bytes"`
+}
+
+func (cfg *BidLimits) validate(errs configErrors) configErrors {
+	if cfg.MaxBidsPerSeat < 0 {
+		errs = append(errs, fmt.Errorf("bid_limits.max_bids_per_seat must be >= 0. Got %d", cfg.MaxBidsPerSeat))
+	}
+	if cfg.MaxBidsPerImp < 0 {
+		errs = append(errs, fmt.Errorf("bid_limits.max_bids_per_imp must be >= 0. Got %d", cfg.MaxBidsPerImp))
+	}
+	if cfg.MaxMarkupBytes < 0 {
+		errs = append(errs, fmt.Errorf("bid_limits.max_markup_bytes must be >= 0. Got %d", cfg.MaxMarkupBytes))
+	}
+	return errs
+}
+
+// BidExpirations holds the exp limits for each media type.
+type BidExpirations struct {
+	Banner BidExpiration `mapstructure:"banner"`
+	Video  BidExpiration `mapstructure:"video"`
+	Audio  BidExpiration `mapstructure:"audio"`
+	Native BidExpiration `mapstructure:"native"`
+}
+
+func (cfg *BidExpirations) validate(errs configErrors) configErrors {
+	errs = cfg.Banner.validate("banner", errs)
+	errs = cfg.Video.validate("video", errs)
+	errs = cfg.Audio.validate("audio", errs)
+	errs = cfg.Native.validate("native", errs)
+	return errs
+}
+
+// BidExpiration limits the exp of bids for one media type. Bids without an exp are given DefaultSeconds,
+// and other values are clamped to the range [MinSeconds, MaxSeconds]. Each setting is ignored if it's 0.
+type BidExpiration struct {
+	DefaultSeconds int `mapstructure:"default_seconds"`
+	MinSeconds     int `mapstructure:"min_seconds"`
+	MaxSeconds     int `mapstructure:"max_seconds"`
+}
+
+func (cfg *BidExpiration) validate(mediaType string, errs configErrors) configErrors {
+	if cfg.DefaultSeconds < 0 || cfg.MinSeconds < 0 || cfg.MaxSeconds < 0 {
+		return append(errs, fmt.Errorf("bid_expiration.%s settings must be >= 0", mediaType))
+	}
+	if cfg.MaxSeconds > 0 && cfg.MinSeconds > cfg.MaxSeconds {
+		errs = append(errs, fmt.Errorf("bid_expiration.%s.min_seconds must not exceed max_seconds. Got %d > %d", mediaType, cfg.MinSeconds, cfg.MaxSeconds))
+	}
+	return errs
+}
+
+// ADomainFilter screens bids by their adomain. A bid fails if any of its domains (or a parent domain)
+// is Denied, or if Allowed is non-empty and any of its domains isn't covered by it.
+type ADomainFilter struct {
+	Allowed []string `mapstructure:"allowed,flow"`
+	Denied  []string `mapstructure:"denied,flow"`
+	// DenyEmpty fails bids which don't declare an adomain at all. Otherwise they pass.
+	DenyEmpty bool `mapstructure:"deny_empty"`
+	// WarnOnly keeps the bids which fail, and only warns about them.
+	WarnOnly bool `mapstructure:"warn_only"`
+}
+
+func (cfg *ADomainFilter) validate(errs configErrors) configErrors {
+	for _, domain := range cfg.Allowed {
+		if strings.TrimSpace(domain) == "" {
+			errs = append(errs, fmt.Errorf("adomain_filter.allowed must not contain empty domains"))
+			break
+		}
+	}
+	for _, domain := range cfg.Denied {
+		if strings.TrimSpace(domain) == "" {
+			errs = append(errs, fmt.Errorf("adomain_filter.denied must not contain empty domains"))
+			break
+		}
+	}
+	return errs
+}
+
+// Debug configures the debug output in responses to test requests.
+type Debug struct {
+	// RedactedFields are masked in the request and response bodies of the debug output, so that publishers
+	// don't see things like user IDs. Each one is a dot-separated path of JSON object keys, like "user.id".
+	RedactedFields []string `mapstructure:"redacted_fields,flow"`
+}
+
+func (cfg *Debug) validate(errs configErrors) configErrors {
+	for _, field := range cfg.RedactedFields {
+		for _, key := range strings.Split(field, ".") {
+			if key == "" {
+				errs = append(errs, fmt.Errorf("debug.redacted_fields must only contain dot-separated JSON object keys. Got %q", field))
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// RequestCompression applies to bidders with gzip_requests enabled. Compressing small bodies costs more
+// CPU than it saves on the wire, so only bodies larger than MinBodyBytes are gzipped.
+type RequestCompression struct {
+	MinBodyBytes int `mapstructure:"min_body_bytes"`
+}
+
+func (cfg *RequestCompression) validate(errs configErrors) configErrors {
+	if cfg.MinBodyBytes < 0 {
+		errs = append(errs, fmt.Errorf("request_compression.min_body_bytes must be >= 0. Got %d", cfg.MinBodyBytes))
+	}
+	return errs
+}
+
 type RequestTimeoutHeaders struct {
 	RequestTimeInQueue    string `mapstructure:"request_time_in_queue"`
 	RequestTimeoutInQueue string `mapstructure:"request_timeout_in_queue"`
@@ -270,6 +520,98 @@ type Adapter struct {
 	// needed for Facebook
 	PlatformID string `mapstructure:"platform_id"`
 	AppSecret  string `mapstructure:"app_secret"`
+
+	// DisableNativeEnrichment passes this Bidder's native markup through untouched, rather than copying
+	// the asset types from the request into it. Use it for Bidders whose native markup isn't IAB compliant.
+	DisableNativeEnrichment bool `mapstructure:"disable_native_enrichment"`
+
+	// Macros are substituted into this Bidder's request URIs and bodies just before they're sent.
+	// Each ${NAME} token is replaced by the value of the macro whose name matches it, ignoring case.
+	// Values are URL-escaped in the URI and JSON-escaped in the body.
+	Macros map[string]string `mapstructure:"macros"`
+
+	// GenerateBidIDs gives a random ID to each bid which this Bidder returns without one. OpenRTB requires bid IDs,
+	// so this is meant for Bidders which leave them blank by mistake.
+	GenerateBidIDs bool `mapstructure:"generate_bid_ids"`
+
+	// GzipRequests sends this Bidder gzipped request bodies, if they're larger than request_compression.min_body_bytes.
+	// Only enable it for Bidders whose servers accept "Content-Encoding: gzip".
+	GzipRequests bool `mapstructure:"gzip_requests"`
+
+	// SplitRequestDeadline gives each of this Bidder's parallel HTTP requests an equal share of the time left in
+	// the auction, so that one hung request can't use up the whole budget. Most Bidders should leave this off.
+	SplitRequestDeadline bool `mapstructure:"split_request_deadline"`
+
+	// HTTPClient gives this Bidder its own connection pool, for partners whose traffic needs more (or fewer) idle
+	// connections than the rest. Any setting left at 0 uses the value from the top-level http_client config.
+	// If all of them are 0, the Bidder shares the top-level client.
+	HTTPClient HTTPClient `mapstructure:"http_client"`
+
+	// LogRequestSampleRate is the fraction of this Bidder's requests whose URI and body are logged, for audits.
+	// For example, 0.001 logs about 1 in 1000 requests. 0 logs none of them.
+	LogRequestSampleRate float64 `mapstructure:"log_request_sample_rate"`
+
+	// SampleCallRate is the fraction of this Bidder's calls which are sent to the exchange's HTTPCallSink,
+	// with the same detail as the httpcalls of test requests. 0 sends none of them.
+	SampleCallRate float64 `mapstructure:"sample_call_rate"`
+
+	// ResponseCache lets identical requests made within the TTL share one response, for Bidders which
+	// always answer them the same way.
+	ResponseCache ResponseCache `mapstructure:"response_cache"`
+
+	// NoContentIsNoBid treats a 204 response from this Bidder as a clean no-bid, without calling its MakeBids.
+	// 204 is the OpenRTB convention for "no bid", but some Bidders' MakeBids handle it in their own way.
+	NoContentIsNoBid bool `mapstructure:"no_content_is_no_bid"`
+
+	// WarnOnEmptyResponse reports a 200 response with an empty (or whitespace-only) body as a warning, rather than
+	// passing it to MakeBids as a no-bid. Use it for Bidders whose empty 200s are known to signal errors.
+	WarnOnEmptyResponse bool `mapstructure:"warn_on_empty_response"`
+
+	// BidExtAllowlist lists the only top-level bid.ext keys which are forwarded from this Bidder's bids.
+	// The rest are removed, so that DSP internals don't reach publishers. If empty, bid.ext passes through untouched.
+	BidExtAllowlist []string `mapstructure:"bid_ext_allowlist,flow"`
+
+	// Redirects limits how this Bidder's endpoint may redirect requests. Each redirect sends the request,
+	// headers included, to another host, so Bidders which never redirect on purpose should disable them.
+	Redirects Redirects `mapstructure:"redirects"`
+
+	// SyntheticLatencyMs delays each of this Bidder's requests before it's sent, so that timeouts can be triggered
+	// on purpose. It's only allowed if enable_chaos_testing is set.
+	SyntheticLatencyMs int `mapstructure:"synthetic_latency_ms"`
+
+	// ReconcileBidTypes checks the type of each bid against the formats of its imp, and retypes the bids which
+	// don't fit from their markup (VAST is video, HTML is banner). Use it for Bidders which guess at the type
+	// of bids for multi-format imps.
+	ReconcileBidTypes bool `mapstructure:"reconcile_bid_types"`
+
+	// TLS sets up mutual TLS for Bidders whose endpoints require a client certificate.
+	// The files are read once at startup, so a restart is needed to pick up rotated certificates.
+	TLS TLSClient `mapstructure:"tls"`
+}
+
+// TLSClient configures the TLS connections to a Bidder's endpoint. The zero value keeps the host's TLS setup.
+type TLSClient struct {
+	// CertFile and KeyFile are the PEM files of the client certificate sent to the Bidder. They must be set together.
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// CAFile is a PEM file of the certificates which may sign the Bidder's server certificate.
+	// If empty, the same certificates are trusted as for the other Bidders.
+	CAFile string `mapstructure:"ca_file"`
+}
+
+// Redirects configures which redirects are followed for a Bidder's requests. The zero value keeps
+// the Go client's default, which follows up to 10 of them.
+type Redirects struct {
+	// Disabled fails the request as soon as the endpoint redirects it.
+	Disabled bool `mapstructure:"disabled"`
+	// Max is the most redirects which are followed for one request. 0 uses the default.
+	Max int `mapstructure:"max"`
+}
+
+// ResponseCache configures the in-memory cache of a Bidder's responses. It's disabled if SizeBytes is 0.
+type ResponseCache struct {
+	SizeBytes  int `mapstructure:"size_bytes"`
+	TTLSeconds int `mapstructure:"ttl_seconds"`
 }
 
 // validateAdapterEndpoint makes sure that an adapter has a valid endpoint
@@ -346,6 +688,28 @@ func validateAdapters(adapterMap map[string]Adapter, errs configErrors) configEr
 
 			// Verify that valid user_sync URLs are specified in the config
 			errs = validateAdapterUserSyncURL(adapter.UserSyncURL, adapterName, errs)
+
+			errs = adapter.HTTPClient.validate(fmt.Sprintf("adapters.%s.http_client", adapterName), errs)
+			if adapter.LogRequestSampleRate < 0 || adapter.LogRequestSampleRate > 1 {
+				errs = append(errs, fmt.Errorf("adapters.%s.log_request_sample_rate must be in the range [0, 1]. Got %g", adapterName, adapter.LogRequestSampleRate))
+			}
+			if adapter.SampleCallRate < 0 || adapter.SampleCallRate > 1 {
+				errs = append(errs, fmt.Errorf("adapters.%s.sample_call_rate must be in the range [0, 1]. Got %g", adapterName, adapter.SampleCallRate))
+			}
+			if adapter.SyntheticLatencyMs < 0 {
+				errs = append(errs, fmt.Errorf("adapters.%s.synthetic_latency_ms must be >= 0. Got %d", adapterName, adapter.SyntheticLatencyMs))
+			}
+			if adapter.Redirects.Max < 0 {
+				errs = append(errs, fmt.Errorf("adapters.%s.redirects.max must be >= 0. Got %d", adapterName, adapter.Redirects.Max))
+			}
+			if (adapter.TLS.CertFile == "") != (adapter.TLS.KeyFile == "") {
+				errs = append(errs, fmt.Errorf("adapters.%s.tls.cert_file and adapters.%s.tls.key_file must be set together", adapterName, adapterName))
+			}
+			if adapter.ResponseCache.SizeBytes < 0 {
+				errs = append(errs, fmt.Errorf("adapters.%s.response_cache.size_bytes must be >= 0. Got %d", adapterName, adapter.ResponseCache.SizeBytes))
+			} else if adapter.ResponseCache.SizeBytes > 0 && adapter.ResponseCache.TTLSeconds <= 0 {
+				errs = append(errs, fmt.Errorf("adapters.%s.response_cache.ttl_seconds must be > 0 when the cache is enabled. Got %d", adapterName, adapter.ResponseCache.TTLSeconds))
+			}
 		}
 	}
 	return errs
@@ -629,9 +993,15 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("http_client.max_idle_connections", 400)
 	v.SetDefault("http_client.max_idle_connections_per_host", 10)
 	v.SetDefault("http_client.idle_connection_timeout_seconds", 60)
+	v.SetDefault("http_client.connect_timeout_ms", 1000)
+	v.SetDefault("http_client.tls_handshake_timeout_ms", 1000)
+	v.SetDefault("http_client.response_header_timeout_ms", 5000)
 	v.SetDefault("http_client_cache.max_idle_connections", 10)
 	v.SetDefault("http_client_cache.max_idle_connections_per_host", 2)
 	v.SetDefault("http_client_cache.idle_connection_timeout_seconds", 60)
+	v.SetDefault("http_client_cache.connect_timeout_ms", 1000)
+	v.SetDefault("http_client_cache.tls_handshake_timeout_ms", 1000)
+	v.SetDefault("http_client_cache.response_header_timeout_ms", 5000)
 	// no metrics configured by default (metrics{host|database|username|password})
 	v.SetDefault("metrics.disabled_metrics.account_adapter_details", false)
 	v.SetDefault("metrics.influxdb.host", "")
@@ -809,6 +1179,8 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("ccpa.enforce", false)
 	v.SetDefault("currency_converter.fetch_url", "https://cdn.jsdelivr.net/gh/prebid/currency-file@1/latest.json")
 	v.SetDefault("currency_converter.fetch_interval_seconds", 1800) // fetch currency rates every 30 minutes
+	v.SetDefault("currency_converter.price_decimals", 0)
+	v.SetDefault("currency_converter.fallback_currency", "")
 	v.SetDefault("default_request.type", "")
 	v.SetDefault("default_request.file.name", "")
 	v.SetDefault("default_request.alias_info", false)
@@ -816,9 +1188,38 @@ func SetupViper(v *viper.Viper, filename string) {
 	v.SetDefault("blacklisted_accts", []string{""})
 	v.SetDefault("account_required", false)
 	v.SetDefault("certificates_file", "")
+	v.SetDefault("deal_targeting_key_prefix", "hb_deal")
 
 	v.SetDefault("request_timeout_headers.request_time_in_queue", "")
 	v.SetDefault("request_timeout_headers.request_timeout_in_queue", "")
+	v.SetDefault("circuit_breaker.failure_threshold", 0)
+	v.SetDefault("circuit_breaker.window_ms", 10000)
+	v.SetDefault("circuit_breaker.cooldown_ms", 30000)
+	v.SetDefault("bid_limits.max_bids_per_seat", 0)
+	v.SetDefault("bid_limits.max_bids_per_imp", 0)
+	v.SetDefault("bid_limits.max_markup_bytes", 0)
+	v.SetDefault("request_compression.min_body_bytes", 1024)
+	v.SetDefault("debug.redacted_fields", []string{})
+	v.SetDefault("max_concurrent_bidder_calls", 0)
+	v.SetDefault("bid_expiration.banner.default_seconds", 0)
+	v.SetDefault("bid_expiration.banner.min_seconds", 0)
+	v.SetDefault("bid_expiration.banner.max_seconds", 0)
+	v.SetDefault("bid_expiration.video.default_seconds", 0)
+	v.SetDefault("bid_expiration.video.min_seconds", 0)
+	v.SetDefault("bid_expiration.video.max_seconds", 0)
+	v.SetDefault("bid_expiration.audio.default_seconds", 0)
+	v.SetDefault("bid_expiration.audio.min_seconds", 0)
+	v.SetDefault("bid_expiration.audio.max_seconds", 0)
+	v.SetDefault("bid_expiration.native.default_seconds", 0)
+	v.SetDefault("bid_expiration.native.min_seconds", 0)
+	v.SetDefault("bid_expiration.native.max_seconds", 0)
+	v.SetDefault("adomain_filter.allowed", []string{})
+	v.SetDefault("adomain_filter.denied", []string{})
+	v.SetDefault("adomain_filter.deny_empty", false)
+	v.SetDefault("adomain_filter.warn_only", false)
+	v.SetDefault("enable_chaos_testing", false)
+	v.SetDefault("request_id_header", "")
+	v.SetDefault("bidder_request_headers", map[string]string{})
 
 	// Set environment variable support:
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -839,6 +1240,30 @@ func setBidderDefaults(v *viper.Viper, bidder string) {
 	v.SetDefault(adapterCfgPrefix+bidder+".disabled", false)
 	v.SetDefault(adapterCfgPrefix+bidder+".partner_id", "")
 	v.SetDefault(adapterCfgPrefix+bidder+".extra_info", "")
+	v.SetDefault(adapterCfgPrefix+bidder+".disable_native_enrichment", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".generate_bid_ids", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".gzip_requests", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".split_request_deadline", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".http_client.max_idle_connections", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".http_client.max_idle_connections_per_host", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".http_client.idle_connection_timeout_seconds", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".http_client.connect_timeout_ms", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".http_client.tls_handshake_timeout_ms", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".http_client.response_header_timeout_ms", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".log_request_sample_rate", 0.0)
+	v.SetDefault(adapterCfgPrefix+bidder+".sample_call_rate", 0.0)
+	v.SetDefault(adapterCfgPrefix+bidder+".response_cache.size_bytes", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".response_cache.ttl_seconds", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".no_content_is_no_bid", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".warn_on_empty_response", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".bid_ext_allowlist", []string{})
+	v.SetDefault(adapterCfgPrefix+bidder+".redirects.disabled", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".redirects.max", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".synthetic_latency_ms", 0)
+	v.SetDefault(adapterCfgPrefix+bidder+".reconcile_bid_types", false)
+	v.SetDefault(adapterCfgPrefix+bidder+".tls.cert_file", "")
+	v.SetDefault(adapterCfgPrefix+bidder+".tls.key_file", "")
+	v.SetDefault(adapterCfgPrefix+bidder+".tls.ca_file", "")
 }
 
 func isValidCookieSize(maxCookieSize int) error {
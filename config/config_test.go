@@ -34,6 +34,42 @@ func TestDefaults(t *testing.T) {
 	cmpInts(t, "metrics.influxdb.collection_rate_seconds", cfg.Metrics.Influxdb.MetricSendInterval, 20)
 	cmpBools(t, "account_adapter_details", cfg.Metrics.Disabled.AccountAdapterDetails, false)
 	cmpStrings(t, "certificates_file", cfg.PemCertsFile, "")
+	cmpStrings(t, "deal_targeting_key_prefix", cfg.DealTargetingKeyPrefix, "hb_deal")
+	cmpInts(t, "circuit_breaker.failure_threshold", cfg.CircuitBreaker.FailureThreshold, 0)
+	cmpInts(t, "circuit_breaker.window_ms", cfg.CircuitBreaker.WindowMillis, 10000)
+	cmpInts(t, "circuit_breaker.cooldown_ms", cfg.CircuitBreaker.CooldownMillis, 30000)
+	cmpInts(t, "bid_limits.max_bids_per_seat", cfg.BidLimits.MaxBidsPerSeat, 0)
+	cmpInts(t, "bid_limits.max_bids_per_imp", cfg.BidLimits.MaxBidsPerImp, 0)
+	cmpInts(t, "bid_limits.max_markup_bytes", cfg.BidLimits.MaxMarkupBytes, 0)
+	cmpInts(t, "request_compression.min_body_bytes", cfg.RequestCompression.MinBodyBytes, 1024)
+	cmpInts(t, "debug.redacted_fields", len(cfg.Debug.RedactedFields), 0)
+	cmpInts(t, "max_concurrent_bidder_calls", cfg.MaxConcurrentBidderCalls, 0)
+	cmpInts(t, "bid_expiration.video.max_seconds", cfg.BidExpiration.Video.MaxSeconds, 0)
+	cmpInts(t, "adomain_filter.denied", len(cfg.ADomainFilter.Denied), 0)
+	cmpBools(t, "adomain_filter.deny_empty", cfg.ADomainFilter.DenyEmpty, false)
+	cmpBools(t, "enable_chaos_testing", cfg.EnableChaosTesting, false)
+	cmpStrings(t, "request_id_header", cfg.RequestIDHeader, "")
+	cmpInts(t, "bidder_request_headers", len(cfg.BidderRequestHeaders), 0)
+	cmpInts(t, "adapters.appnexus.synthetic_latency_ms", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].SyntheticLatencyMs, 0)
+	cmpInts(t, "currency_converter.price_decimals", cfg.CurrencyConverter.PriceDecimals, 0)
+	cmpStrings(t, "currency_converter.fallback_currency", cfg.CurrencyConverter.FallbackCurrency, "")
+	cmpBools(t, "adapters.appnexus.gzip_requests", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].GzipRequests, false)
+	cmpBools(t, "adapters.appnexus.generate_bid_ids", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].GenerateBidIDs, false)
+	cmpBools(t, "adapters.appnexus.split_request_deadline", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].SplitRequestDeadline, false)
+	cmpFloats(t, "adapters.appnexus.log_request_sample_rate", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].LogRequestSampleRate, 0.0)
+	cmpFloats(t, "adapters.appnexus.sample_call_rate", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].SampleCallRate, 0.0)
+	cmpBools(t, "adapters.appnexus.no_content_is_no_bid", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].NoContentIsNoBid, false)
+	cmpBools(t, "adapters.appnexus.warn_on_empty_response", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].WarnOnEmptyResponse, false)
+	cmpBools(t, "adapters.appnexus.reconcile_bid_types", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].ReconcileBidTypes, false)
+	cmpBools(t, "adapters.appnexus.redirects.disabled", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].Redirects.Disabled, false)
+	cmpStrings(t, "adapters.appnexus.tls.cert_file", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].TLS.CertFile, "")
+	cmpStrings(t, "adapters.appnexus.tls.ca_file", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].TLS.CAFile, "")
+	cmpInts(t, "adapters.appnexus.bid_ext_allowlist", len(cfg.Adapters[string(openrtb_ext.BidderAppnexus)].BidExtAllowlist), 0)
+	cmpInts(t, "adapters.appnexus.response_cache.size_bytes", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].ResponseCache.SizeBytes, 0)
+	cmpInts(t, "http_client.connect_timeout_ms", cfg.Client.ConnectTimeoutMs, 1000)
+	cmpInts(t, "http_client.tls_handshake_timeout_ms", cfg.Client.TLSHandshakeTimeoutMs, 1000)
+	cmpInts(t, "http_client.response_header_timeout_ms", cfg.Client.ResponseHeaderTimeoutMs, 5000)
+	cmpInts(t, "adapters.appnexus.http_client.max_idle_connections_per_host", cfg.Adapters[string(openrtb_ext.BidderAppnexus)].HTTPClient.MaxIdleConnsPerHost, 0)
 }
 
 var fullConfig = []byte(`
@@ -187,6 +223,11 @@ func cmpInts(t *testing.T, key string, a int, b int) {
 	assert.Equal(t, a, b, "%s: %d != %d", key, a, b)
 }
 
+func cmpFloats(t *testing.T, key string, a float64, b float64) {
+	t.Helper()
+	assert.Equal(t, a, b, "%s: %f != %f", key, a, b)
+}
+
 func cmpBools(t *testing.T, key string, a bool, b bool) {
 	t.Helper()
 	assert.Equal(t, a, b, "%s: %t != %t", key, a, b)
@@ -321,6 +362,7 @@ func TestUnmarshalAdapterExtraInfo(t *testing.T) {
 
 func TestValidConfig(t *testing.T) {
 	cfg := Configuration{
+		DealTargetingKeyPrefix: "hb_deal",
 		StoredRequests: StoredRequests{
 			Files: true,
 			InMemoryCache: InMemoryCache{
@@ -357,6 +399,154 @@ func TestNegativeRequestSize(t *testing.T) {
 	assertOneError(t, cfg.validate(), "cfg.max_request_size must be >= 0. Got -1")
 }
 
+func TestDealTargetingKeyPrefix(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.DealTargetingKeyPrefix = "pb_deal"
+	assert.Empty(t, cfg.validate())
+
+	cfg.DealTargetingKeyPrefix = ""
+	assertOneError(t, cfg.validate(), `deal_targeting_key_prefix must be between 1 and 7 characters long. Got ""`)
+
+	cfg.DealTargetingKeyPrefix = "hb_deal_id"
+	assertOneError(t, cfg.validate(), `deal_targeting_key_prefix must be between 1 and 7 characters long. Got "hb_deal_id"`)
+}
+
+func TestCircuitBreakerWithoutCooldown(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.CircuitBreaker.FailureThreshold = 5
+	cfg.CircuitBreaker.CooldownMillis = 0
+	assertOneError(t, cfg.validate(), "circuit_breaker.cooldown_ms must be positive if circuit_breaker.failure_threshold is defined. Got 0")
+}
+
+func TestNegativeBidLimit(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.BidLimits.MaxBidsPerImp = -1
+	assertOneError(t, cfg.validate(), "bid_limits.max_bids_per_imp must be >= 0. Got -1")
+}
+
+func TestNegativeMaxMarkupBytes(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.BidLimits.MaxMarkupBytes = -1
+	assertOneError(t, cfg.validate(), "bid_limits.max_markup_bytes must be >= 0. Got -1")
+}
+
+func TestNegativeCompressionThreshold(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.RequestCompression.MinBodyBytes = -1
+	assertOneError(t, cfg.validate(), "request_compression.min_body_bytes must be >= 0. Got -1")
+}
+
+func TestNegativeAdapterHTTPClient(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	adapterCfg := cfg.Adapters[string(openrtb_ext.BidderAppnexus)]
+	adapterCfg.HTTPClient.MaxIdleConnsPerHost = -1
+	cfg.Adapters[string(openrtb_ext.BidderAppnexus)] = adapterCfg
+	assertOneError(t, cfg.validate(), "adapters.appnexus.http_client settings must be >= 0")
+}
+
+func TestNegativeHTTPClientTimeout(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.Client.ConnectTimeoutMs = -1
+	assertOneError(t, cfg.validate(), "http_client settings must be >= 0")
+}
+
+func TestTLSCertWithoutKey(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	adapterCfg := cfg.Adapters[string(openrtb_ext.BidderAppnexus)]
+	adapterCfg.TLS.CertFile = "/etc/pbs/appnexus.pem"
+	cfg.Adapters[string(openrtb_ext.BidderAppnexus)] = adapterCfg
+	assertOneError(t, cfg.validate(), "adapters.appnexus.tls.cert_file and adapters.appnexus.tls.key_file must be set together")
+}
+
+func TestNegativeMaxRedirects(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	adapterCfg := cfg.Adapters[string(openrtb_ext.BidderAppnexus)]
+	adapterCfg.Redirects.Max = -1
+	cfg.Adapters[string(openrtb_ext.BidderAppnexus)] = adapterCfg
+	assertOneError(t, cfg.validate(), "adapters.appnexus.redirects.max must be >= 0. Got -1")
+}
+
+func TestSyntheticLatencyNeedsChaosTesting(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	adapterCfg := cfg.Adapters[string(openrtb_ext.BidderAppnexus)]
+	adapterCfg.SyntheticLatencyMs = 500
+	cfg.Adapters[string(openrtb_ext.BidderAppnexus)] = adapterCfg
+	assertOneError(t, cfg.validate(), "adapters.appnexus.synthetic_latency_ms is only allowed if enable_chaos_testing is true")
+
+	cfg.EnableChaosTesting = true
+	assert.Empty(t, cfg.validate())
+}
+
+func TestInvalidLogRequestSampleRate(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	adapterCfg := cfg.Adapters[string(openrtb_ext.BidderAppnexus)]
+	adapterCfg.LogRequestSampleRate = 1.5
+	cfg.Adapters[string(openrtb_ext.BidderAppnexus)] = adapterCfg
+	assertOneError(t, cfg.validate(), "adapters.appnexus.log_request_sample_rate must be in the range [0, 1]. Got 1.5")
+}
+
+func TestInvalidSampleCallRate(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	adapterCfg := cfg.Adapters[string(openrtb_ext.BidderAppnexus)]
+	adapterCfg.SampleCallRate = -0.5
+	cfg.Adapters[string(openrtb_ext.BidderAppnexus)] = adapterCfg
+	assertOneError(t, cfg.validate(), "adapters.appnexus.sample_call_rate must be in the range [0, 1]. Got -0.5")
+}
+
+func TestResponseCacheWithoutTTL(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	adapterCfg := cfg.Adapters[string(openrtb_ext.BidderAppnexus)]
+	adapterCfg.ResponseCache.SizeBytes = 1024 * 1024
+	cfg.Adapters[string(openrtb_ext.BidderAppnexus)] = adapterCfg
+	assertOneError(t, cfg.validate(), "adapters.appnexus.response_cache.ttl_seconds must be > 0 when the cache is enabled. Got 0")
+}
+
+func TestNegativeMaxConcurrentBidderCalls(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.MaxConcurrentBidderCalls = -1
+	assertOneError(t, cfg.validate(), "max_concurrent_bidder_calls must be >= 0. Got -1")
+}
+
+func TestInvalidBidExpiration(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.BidExpiration.Video.MinSeconds = 600
+	cfg.BidExpiration.Video.MaxSeconds = 300
+	assertOneError(t, cfg.validate(), "bid_expiration.video.min_seconds must not exceed max_seconds. Got 600 > 300")
+}
+
+func TestEmptyADomainFilterDomain(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.ADomainFilter.Denied = []string{"example.com", " "}
+	assertOneError(t, cfg.validate(), "adomain_filter.denied must not contain empty domains")
+}
+
+func TestInvalidPriceDecimals(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.CurrencyConverter.PriceDecimals = -1
+	assertOneError(t, cfg.validate(), "currency_converter.price_decimals must be in the range [0, 10]. Got -1")
+}
+
+func TestInvalidBidderRequestHeaders(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.BidderRequestHeaders = map[string]string{"x forwarded host": "pbs.example.com"}
+	assertOneError(t, cfg.validate(), `bidder_request_headers must only contain valid header names. Got "x forwarded host"`)
+
+	cfg.BidderRequestHeaders = map[string]string{"x-request-id": "${REQUEST_ID}\r\nX-Evil: 1"}
+	assertOneError(t, cfg.validate(), "bidder_request_headers.x-request-id must not contain line breaks")
+}
+
+func TestInvalidFallbackCurrency(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.CurrencyConverter.FallbackCurrency = "DOLLARS"
+	assertOneError(t, cfg.validate(), `currency_converter.fallback_currency must be an ISO 4217 currency code. Got "DOLLARS"`)
+}
+
+func TestInvalidRedactedField(t *testing.T) {
+	cfg := newDefaultConfig(t)
+	cfg.Debug.RedactedFields = []string{"user.id", "device..ifa"}
+	assertOneError(t, cfg.validate(), `debug.redacted_fields must only contain dot-separated JSON object keys. Got "device..ifa"`)
+}
+
 func TestNegativeVendorID(t *testing.T) {
 	cfg := newDefaultConfig(t)
 	cfg.GDPR.HostVendorID = -1
@@ -11,6 +11,7 @@ const (
 	BidderTemporarilyDisabledErrorCode
 	BlacklistedAcctErrorCode
 	AcctRequiredErrorCode
+	BidderUnavailableErrorCode
 )
 
 // Defines numeric codes for well-known warnings.
@@ -19,6 +20,15 @@ const (
 	InvalidPrivacyConsentWarningCode = iota + 10000
 )
 
+// Defines numeric subcodes which tell apart the different kinds of BadServerResponse errors.
+const (
+	UnknownBadServerResponseSubcode = iota
+	ClientErrorStatusSubcode
+	ServerErrorStatusSubcode
+	UnexpectedStatusSubcode
+	MalformedResponseSubcode
+)
+
 // Coder provides an error or warning code with severity.
 type Coder interface {
 	Code() int
@@ -32,3 +42,11 @@ func ReadCode(err error) int {
 	}
 	return UnknownErrorCode
 }
+
+// ReadSubcode returns the subcode of a BadServerResponse, or 0 for any other error.
+func ReadSubcode(err error) int {
+	if e, ok := err.(*BadServerResponse); ok {
+		return e.Subcode
+	}
+	return 0
+}
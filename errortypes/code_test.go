@@ -22,3 +22,9 @@ func TestReadCodeWithCodeNotDefined(t *testing.T) {
 
 	assert.Equal(t, result, UnknownErrorCode)
 }
+
+func TestReadSubcode(t *testing.T) {
+	assert.Equal(t, ServerErrorStatusSubcode, ReadSubcode(&BadServerResponse{Subcode: ServerErrorStatusSubcode}))
+	assert.Equal(t, UnknownBadServerResponseSubcode, ReadSubcode(&BadServerResponse{}))
+	assert.Equal(t, 0, ReadSubcode(&Timeout{}), "Other errors have no subcode.")
+}
@@ -109,8 +109,12 @@ func (err *AcctRequired) Severity() Severity {
 //
 // These should not be used to log _connection_ errors (e.g. "couldn't find host"),
 // which may indicate config issues for the PBS host company
+//
+// Subcode should be one of the BadServerResponse subcodes, so that API clients can tell the causes apart
+// without parsing the Message.
 type BadServerResponse struct {
 	Message string
+	Subcode int
 }
 
 func (err *BadServerResponse) Error() string {
@@ -165,6 +169,24 @@ func (err *BidderTemporarilyDisabled) Severity() Severity {
 	return SeverityWarning
 }
 
+// BidderUnavailable should be used when the exchange skips calling a bidder because its endpoint
+// has been failing repeatedly, so that the bidder's absence is still explained to the publisher.
+type BidderUnavailable struct {
+	Message string
+}
+
+func (err *BidderUnavailable) Error() string {
+	return err.Message
+}
+
+func (err *BidderUnavailable) Code() int {
+	return BidderUnavailableErrorCode
+}
+
+func (err *BidderUnavailable) Severity() Severity {
+	return SeverityFatal
+}
+
 // Warning is a generic non-fatal error.
 type Warning struct {
 	Message string
@@ -0,0 +1,160 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/pbsmetrics"
+)
+
+// adomainFilter screens bids by their advertiser domains. One is made for each auction, by combining
+// the host's config with the request's ext.prebid.adomainfilter.
+//
+// A domain matches a list if it, or any of its parent domains, is on it. "example.com" covers "ads.example.com".
+type adomainFilter struct {
+	// allowed holds every allow list which applies. A bid's domains must match all of them.
+	allowed   []map[string]bool
+	denied    map[string]bool
+	denyEmpty bool
+	warnOnly  bool
+}
+
+// newADomainFilter returns nil if no bids could fail the filter.
+func newADomainFilter(cfg config.ADomainFilter, ext *openrtb_ext.ExtRequestPrebidADomainFilter) *adomainFilter {
+	filter := &adomainFilter{
+		denied:    make(map[string]bool),
+		denyEmpty: cfg.DenyEmpty,
+		warnOnly:  cfg.WarnOnly,
+	}
+	filter.addAllowed(cfg.Allowed)
+	filter.addDenied(cfg.Denied)
+	if ext != nil {
+		filter.addAllowed(ext.Allowed)
+		filter.addDenied(ext.Denied)
+		if ext.DenyEmpty != nil {
+			filter.denyEmpty = *ext.DenyEmpty
+		}
+		if ext.WarnOnly != nil {
+			filter.warnOnly = *ext.WarnOnly
+		}
+	}
+
+	if len(filter.allowed) == 0 && len(filter.denied) == 0 && !filter.denyEmpty {
+		return nil
+	}
+	return filter
+}
+
+func (f *adomainFilter) addAllowed(domains []string) {
+	if len(domains) == 0 {
+		return
+	}
+	allowed := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			allowed[domain] = true
+		}
+	}
+	f.allowed = append(f.allowed, allowed)
+}
+
+func (f *adomainFilter) addDenied(domains []string) {
+	for _, domain := range domains {
+		if domain = normalizeDomain(domain); domain != "" {
+			f.denied[domain] = true
+		}
+	}
+}
+
+// check returns the reason why a bid with these domains fails the filter, along with the offending domain.
+// The reason is empty if the bid passes.
+func (f *adomainFilter) check(adomains []string) (pbsmetrics.BidDropReason, string) {
+	if len(adomains) == 0 {
+		if f.denyEmpty {
+			return pbsmetrics.BidDropReasonADomainMissing, ""
+		}
+		return "", ""
+	}
+	for _, domain := range adomains {
+		if domainMatches(f.denied, domain) {
+			return pbsmetrics.BidDropReasonADomainDenied, domain
+		}
+	}
+	for _, domain := range adomains {
+		for _, allowed := range f.allowed {
+			if !domainMatches(allowed, domain) {
+				return pbsmetrics.BidDropReasonADomainNotAllowed, domain
+			}
+		}
+	}
+	return "", ""
+}
+
+// apply removes the bids which fail the filter, unless it only warns. It returns the number of bids dropped
+// for each reason, and a warning for each bid which failed.
+// Bids with no openrtb.Bid are left for the validation in bidder_validate_bids.go to report.
+func (f *adomainFilter) apply(seatBid *pbsOrtbSeatBid) (map[pbsmetrics.BidDropReason]int, []error) {
+	var errs []error
+	var dropped map[pbsmetrics.BidDropReason]int
+	kept := seatBid.bids[:0]
+	for _, bid := range seatBid.bids {
+		if bid.bid == nil {
+			kept = append(kept, bid)
+			continue
+		}
+		reason, domain := f.check(bid.bid.ADomain)
+		if reason == "" {
+			kept = append(kept, bid)
+			continue
+		}
+
+		var problem string
+		switch reason {
+		case pbsmetrics.BidDropReasonADomainMissing:
+			problem = "it has no adomain"
+		case pbsmetrics.BidDropReasonADomainDenied:
+			problem = fmt.Sprintf("its adomain %s is denied", domain)
+		default:
+			problem = fmt.Sprintf("its adomain %s is not allowed", domain)
+		}
+		if f.warnOnly {
+			kept = append(kept, bid)
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("Bid %s would have been dropped because %s.", bid.bid.ID, problem),
+			})
+			continue
+		}
+		if dropped == nil {
+			dropped = make(map[pbsmetrics.BidDropReason]int)
+		}
+		dropped[reason]++
+		errs = append(errs, &errortypes.Warning{
+			Message: fmt.Sprintf("Bid %s was dropped because %s.", bid.bid.ID, problem),
+		})
+	}
+	seatBid.bids = kept
+	return dropped, errs
+}
+
+// domainMatches returns true if the domain, or one of its parent domains, is in the list.
+func domainMatches(list map[string]bool, domain string) bool {
+	domain = normalizeDomain(domain)
+	for domain != "" {
+		if list[domain] {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
@@ -0,0 +1,96 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/pbsmetrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewADomainFilterDisabled(t *testing.T) {
+	assert.Nil(t, newADomainFilter(config.ADomainFilter{}, nil))
+	assert.Nil(t, newADomainFilter(config.ADomainFilter{WarnOnly: true}, &openrtb_ext.ExtRequestPrebidADomainFilter{}))
+}
+
+func TestADomainFilterCheck(t *testing.T) {
+	denyEmpty := true
+	filter := newADomainFilter(config.ADomainFilter{
+		Allowed: []string{"example.com", "other.com"},
+		Denied:  []string{"bad.example.com"},
+	}, &openrtb_ext.ExtRequestPrebidADomainFilter{
+		Allowed:   []string{"Example.com"},
+		DenyEmpty: &denyEmpty,
+	})
+
+	testCases := []struct {
+		description    string
+		adomains       []string
+		expectedReason pbsmetrics.BidDropReason
+		expectedDomain string
+	}{
+		{"allowed domain", []string{"example.com"}, "", ""},
+		{"allowed subdomain", []string{"ads.EXAMPLE.com."}, "", ""},
+		{"denied subdomain", []string{"example.com", "x.bad.example.com"}, pbsmetrics.BidDropReasonADomainDenied, "x.bad.example.com"},
+		{"not on the request's allow list", []string{"other.com"}, pbsmetrics.BidDropReasonADomainNotAllowed, "other.com"},
+		{"not on any allow list", []string{"example.com", "unknown.com"}, pbsmetrics.BidDropReasonADomainNotAllowed, "unknown.com"},
+		{"lookalike domain", []string{"notexample.com"}, pbsmetrics.BidDropReasonADomainNotAllowed, "notexample.com"},
+		{"missing adomain", nil, pbsmetrics.BidDropReasonADomainMissing, ""},
+	}
+
+	for _, test := range testCases {
+		reason, domain := filter.check(test.adomains)
+		assert.Equal(t, test.expectedReason, reason, test.description)
+		assert.Equal(t, test.expectedDomain, domain, test.description)
+	}
+}
+
+func TestADomainFilterApply(t *testing.T) {
+	filter := newADomainFilter(config.ADomainFilter{Denied: []string{"bad.com"}, DenyEmpty: true}, nil)
+	seatBid := &pbsOrtbSeatBid{
+		bids: []*pbsOrtbBid{
+			{bid: &openrtb.Bid{ID: "good", ADomain: []string{"good.com"}}},
+			{bid: &openrtb.Bid{ID: "bad", ADomain: []string{"bad.com"}}},
+			{bid: &openrtb.Bid{ID: "empty"}},
+			{bid: nil},
+		},
+	}
+
+	dropped, errs := filter.apply(seatBid)
+
+	assert.Equal(t, map[pbsmetrics.BidDropReason]int{
+		pbsmetrics.BidDropReasonADomainDenied:  1,
+		pbsmetrics.BidDropReasonADomainMissing: 1,
+	}, dropped)
+	if assert.Len(t, seatBid.bids, 2) {
+		assert.Equal(t, "good", seatBid.bids[0].bid.ID)
+		assert.Nil(t, seatBid.bids[1].bid)
+	}
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "Bid bad was dropped because its adomain bad.com is denied.", errs[0].Error())
+		assert.Equal(t, "Bid empty was dropped because it has no adomain.", errs[1].Error())
+	}
+}
+
+func TestADomainFilterWarnOnly(t *testing.T) {
+	warnOnly := true
+	filter := newADomainFilter(config.ADomainFilter{Allowed: []string{"good.com"}}, &openrtb_ext.ExtRequestPrebidADomainFilter{WarnOnly: &warnOnly})
+	seatBid := &pbsOrtbSeatBid{
+		bids: []*pbsOrtbBid{
+			{bid: &openrtb.Bid{ID: "good", ADomain: []string{"good.com"}}},
+			{bid: &openrtb.Bid{ID: "other", ADomain: []string{"other.com"}}},
+		},
+	}
+
+	dropped, errs := filter.apply(seatBid)
+
+	assert.Empty(t, dropped)
+	assert.Len(t, seatBid.bids, 2)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "Bid other would have been dropped because its adomain other.com is not allowed.", errs[0].Error())
+	}
+}
@@ -0,0 +1,103 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// impFormats returns the bid types which the imp accepts, in a fixed order.
+func impFormats(imp *openrtb.Imp) []openrtb_ext.BidType {
+	var formats []openrtb_ext.BidType
+	if imp.Banner != nil {
+		formats = append(formats, openrtb_ext.BidTypeBanner)
+	}
+	if imp.Video != nil {
+		formats = append(formats, openrtb_ext.BidTypeVideo)
+	}
+	if imp.Audio != nil {
+		formats = append(formats, openrtb_ext.BidTypeAudio)
+	}
+	if imp.Native != nil {
+		formats = append(formats, openrtb_ext.BidTypeNative)
+	}
+	return formats
+}
+
+// markupBidType guesses the bid type from the creative markup. It returns "" if the markup doesn't give it away.
+func markupBidType(adm string) openrtb_ext.BidType {
+	markup := strings.TrimSpace(adm)
+	switch {
+	case markup == "":
+		return ""
+	case strings.Contains(strings.ToUpper(markup), "<VAST"):
+		return openrtb_ext.BidTypeVideo
+	case strings.HasPrefix(markup, "{") && strings.Contains(markup, `"assets"`):
+		return openrtb_ext.BidTypeNative
+	case strings.HasPrefix(markup, "<"):
+		return openrtb_ext.BidTypeBanner
+	}
+	return ""
+}
+
+// reconcileBidTypes checks the type of each bid against the formats of its imp. Bids which were given a type
+// their imp doesn't accept, or which were typed against their markup on a multi-format imp, are retyped
+// from their markup. If the markup doesn't help, a bid for a single-format imp gets that format.
+//
+// It returns a warning for each bid whose type it had to guess. Bids for unknown imps are left alone.
+func reconcileBidTypes(bids []*adapters.TypedBid, request *openrtb.BidRequest) []error {
+	var errs []error
+	for _, typedBid := range bids {
+		if typedBid.Bid == nil {
+			continue
+		}
+		var imp *openrtb.Imp
+		for i := range request.Imp {
+			if request.Imp[i].ID == typedBid.Bid.ImpID {
+				imp = &request.Imp[i]
+				break
+			}
+		}
+		if imp == nil {
+			continue
+		}
+
+		formats := impFormats(imp)
+		declaredOK := containsBidType(formats, typedBid.BidType)
+		guessed := markupBidType(typedBid.Bid.AdM)
+		if guessed != "" && !containsBidType(formats, guessed) {
+			guessed = ""
+		}
+
+		var reason string
+		switch {
+		case declaredOK && (guessed == "" || guessed == typedBid.BidType):
+			continue
+		case guessed != "":
+			reason = fmt.Sprintf("its markup looks like %s", guessed)
+		case !declaredOK && len(formats) == 1:
+			guessed = formats[0]
+			reason = fmt.Sprintf("imp %s only accepts %s", imp.ID, guessed)
+		default:
+			continue
+		}
+		errs = append(errs, &errortypes.Warning{
+			Message: fmt.Sprintf("Bid %s was declared as %q, but %s. It was treated as %s.", typedBid.Bid.ID, typedBid.BidType, reason, guessed),
+		})
+		typedBid.BidType = guessed
+	}
+	return errs
+}
+
+func containsBidType(types []openrtb_ext.BidType, bidType openrtb_ext.BidType) bool {
+	for _, t := range types {
+		if t == bidType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkupBidType(t *testing.T) {
+	testCases := []struct {
+		adm      string
+		expected openrtb_ext.BidType
+	}{
+		{adm: "", expected: ""},
+		{adm: `<?xml version="1.0"?><VAST version="3.0"></VAST>`, expected: openrtb_ext.BidTypeVideo},
+		{adm: `  <vast version="2.0"></vast>`, expected: openrtb_ext.BidTypeVideo},
+		{adm: `<div><script src="creative.js"></script></div>`, expected: openrtb_ext.BidTypeBanner},
+		{adm: `{"assets":[{"id":1,"title":{"text":"title"}}]}`, expected: openrtb_ext.BidTypeNative},
+		{adm: `https://bidder.com/creative`, expected: ""},
+	}
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, markupBidType(test.adm), test.adm)
+	}
+}
+
+func TestReconcileBidTypes(t *testing.T) {
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{
+			{ID: "multi", Banner: &openrtb.Banner{}, Video: &openrtb.Video{}},
+			{ID: "video-only", Video: &openrtb.Video{}},
+		},
+	}
+	vast := `<VAST version="3.0"></VAST>`
+	html := `<div>creative</div>`
+
+	testCases := []struct {
+		description  string
+		bid          *adapters.TypedBid
+		expectedType openrtb_ext.BidType
+		expectWarn   bool
+	}{
+		{
+			description:  "Types which fit the imp and markup are kept",
+			bid:          &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b", ImpID: "multi", AdM: vast}, BidType: openrtb_ext.BidTypeVideo},
+			expectedType: openrtb_ext.BidTypeVideo,
+		},
+		{
+			description:  "VAST markup typed as banner on a multi-format imp becomes video",
+			bid:          &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b", ImpID: "multi", AdM: vast}, BidType: openrtb_ext.BidTypeBanner},
+			expectedType: openrtb_ext.BidTypeVideo,
+			expectWarn:   true,
+		},
+		{
+			description:  "HTML markup without a type on a multi-format imp becomes banner",
+			bid:          &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b", ImpID: "multi", AdM: html}},
+			expectedType: openrtb_ext.BidTypeBanner,
+			expectWarn:   true,
+		},
+		{
+			description:  "Types the imp doesn't accept fall back to its only format",
+			bid:          &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b", ImpID: "video-only", NURL: "http://bidder.com/vast"}, BidType: openrtb_ext.BidTypeBanner},
+			expectedType: openrtb_ext.BidTypeVideo,
+			expectWarn:   true,
+		},
+		{
+			description:  "Markup which doesn't fit the imp isn't used to guess",
+			bid:          &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b", ImpID: "video-only", AdM: html}, BidType: openrtb_ext.BidTypeVideo},
+			expectedType: openrtb_ext.BidTypeVideo,
+		},
+		{
+			description:  "Bids without markup on a multi-format imp keep their declared type",
+			bid:          &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b", ImpID: "multi"}, BidType: openrtb_ext.BidTypeBanner},
+			expectedType: openrtb_ext.BidTypeBanner,
+		},
+		{
+			description:  "Bids for unknown imps are left alone",
+			bid:          &adapters.TypedBid{Bid: &openrtb.Bid{ID: "b", ImpID: "unknown", AdM: vast}, BidType: openrtb_ext.BidTypeBanner},
+			expectedType: openrtb_ext.BidTypeBanner,
+		},
+	}
+
+	for _, test := range testCases {
+		errs := reconcileBidTypes([]*adapters.TypedBid{test.bid}, request)
+
+		assert.Equal(t, test.expectedType, test.bid.BidType, test.description)
+		if test.expectWarn {
+			if assert.Len(t, errs, 1, test.description) {
+				assert.IsType(t, &errortypes.Warning{}, errs[0], test.description)
+			}
+		} else {
+			assert.Empty(t, errs, test.description)
+		}
+	}
+}
+
+func TestReconcileBidTypesWarning(t *testing.T) {
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{{ID: "multi", Banner: &openrtb.Banner{}, Video: &openrtb.Video{}}},
+	}
+	bids := []*adapters.TypedBid{
+		{Bid: &openrtb.Bid{ID: "bid-id", ImpID: "multi", AdM: `<VAST version="3.0"></VAST>`}, BidType: openrtb_ext.BidTypeBanner},
+	}
+
+	errs := reconcileBidTypes(bids, request)
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, `Bid bid-id was declared as "banner", but its markup looks like video. It was treated as video.`, errs[0].Error())
+	}
+}
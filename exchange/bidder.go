@@ -2,22 +2,35 @@ package exchange
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"mime"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
+	uuid "github.com/gofrs/uuid"
+	"github.com/golang/glog"
 	"github.com/mxmCherry/openrtb"
 	nativeRequests "github.com/mxmCherry/openrtb/native/request"
 	nativeResponse "github.com/mxmCherry/openrtb/native/response"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currencies"
 	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/pbsmetrics"
 	"golang.org/x/net/context/ctxhttp"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 // adaptedBidder defines the contract needed to participate in an Auction within an Exchange.
@@ -42,7 +55,36 @@ type adaptedBidder interface {
 	//
 	// Any errors will be user-facing in the API.
 	// Error messages should help publishers understand what might account for "bad" bids.
-	requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error)
+	//
+	// Implementations which can't support some of the options may ignore them.
+	requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (*pbsOrtbSeatBid, []error)
+}
+
+// bidRequestOptions holds the per-auction settings for a single call to requestBid.
+type bidRequestOptions struct {
+	// allowedImps holds the only Imp IDs which this bidder may bid on. If nil, bids for any Imp are kept.
+	allowedImps map[string]bool
+	// callLimiter is shared by all the bidders in the auction. If nil, their HTTP calls aren't limited.
+	callLimiter *callLimiter
+	// adomainFilter screens the bids by their adomain. If nil, bids aren't screened.
+	adomainFilter *adomainFilter
+	// bidCallback is called on each bid once its price has been adjusted and converted. If nil, bids are left alone.
+	bidCallback bidCallback
+	// fallbackCurrency is used for bids which can't be converted to any currency in request.cur.
+	// If "", those bids are dropped.
+	fallbackCurrency string
+}
+
+// bidCallback applies custom business rules to a bid before it enters the seat, like marking up its price
+// or tagging it. It may change the bid. If it returns an error, the bid is dropped and the error is reported.
+//
+// The bidder is the name which requestBid was called with, so it may be an alias.
+type bidCallback func(bidder openrtb_ext.BidderName, bid *pbsOrtbBid) error
+
+// winNotifyingBidder is implemented by adaptedBidders which may want to know which of their bids won.
+type winNotifyingBidder interface {
+	// notifyWin must not block, because it is called while the auction response is being built.
+	notifyWin(bid *pbsOrtbBid)
 }
 
 // pbsOrtbBid is a Bid returned by an adaptedBidder.
@@ -52,12 +94,18 @@ type adaptedBidder interface {
 // pbsOrtbBid.bidTargets does not need to be filled out by the Bidder. It will be set later by the exchange.
 // pbsOrtbBid.bidVideo is optional but should be filled out by the Bidder if bidType is video.
 // pbsOrtbBid.dealPriority will become "response.seatbid[i].bid.dealPriority" in the final OpenRTB response.
+// pbsOrtbBid.seat is optional. If set, it will become "response.seatbid[i].seat" instead of the Bidder's name.
+// pbsOrtbBid.httpCall will become "response.seatbid[i].bid.ext.prebid.httpcall" in the final OpenRTB response.
 type pbsOrtbBid struct {
 	bid          *openrtb.Bid
 	bidType      openrtb_ext.BidType
 	bidTargets   map[string]string
 	bidVideo     *openrtb_ext.ExtBidPrebidVideo
 	dealPriority int
+	seat         string
+	// httpCall is the index in pbsOrtbSeatBid.httpCalls of the call which returned this bid.
+	// It's only set for test requests, since that's the only time httpCalls is filled.
+	httpCall *int
 }
 
 // pbsOrtbSeatBid is a SeatBid returned by an adaptedBidder.
@@ -75,26 +123,255 @@ type pbsOrtbSeatBid struct {
 	// ext contains the extension for this seatbid.
 	// if len(bids) > 0, this will become response.seatbid[i].ext.{bidder} on the final OpenRTB response.
 	// if len(bids) == 0, this will be ignored because the OpenRTB spec doesn't allow a SeatBid with 0 Bids.
+	// Use setExtField and getExtField to add or read single keys.
 	ext json.RawMessage
+	// conversions lists each distinct conversion from another currency applied to the bids. Like httpCalls, it's only
+	// populated if the request.test == 1, and will become response.ext.debug.currencyconversions.{bidder}.
+	conversions []openrtb_ext.ExtCurrencyConversion
+	// partial is true if some of the requests made for this seat failed while others succeeded, so the bids
+	// may not be all the Bidder would have offered. It doesn't affect which bids are returned.
+	partial bool
+	// partialTimeout is true if the seat is partial because at least one of the failed requests timed out.
+	partialTimeout bool
+}
+
+// recordConversion adds the conversion to the seat's debug info, unless it's already there.
+func (s *pbsOrtbSeatBid) recordConversion(from string, to string, rate float64) {
+	conversion := openrtb_ext.ExtCurrencyConversion{From: from, To: to, Rate: rate}
+	for _, recorded := range s.conversions {
+		if recorded == conversion {
+			return
+		}
+	}
+	s.conversions = append(s.conversions, conversion)
 }
 
 // adaptBidder converts an adapters.Bidder into an exchange.adaptedBidder.
 //
 // The name refers to the "Adapter" architecture pattern, and should not be confused with a Prebid "Adapter"
 // (which is being phased out and replaced by Bidder for OpenRTB auctions)
-func adaptBidder(bidder adapters.Bidder, client *http.Client) adaptedBidder {
+func adaptBidder(bidder adapters.Bidder, client *http.Client, cfg *config.Configuration, me pbsmetrics.MetricsEngine, name openrtb_ext.BidderName) adaptedBidder {
+	adapterCfg := cfg.Adapters[strings.ToLower(string(name))]
+	tlsConfig, err := bidderTLSConfig(adapterCfg.TLS)
+	if err != nil {
+		glog.Fatalf("Failed to set up TLS for bidder %s: %v", name, err)
+	}
 	return &bidderAdapter{
-		Bidder: bidder,
-		Client: client,
+		Bidder:     bidder,
+		BidderName: name,
+		Client:     withRedirectPolicy(bidderClient(client, cfg.Client, adapterCfg.HTTPClient, tlsConfig), adapterCfg.Redirects),
+		me:         me,
+		breaker:    newCircuitBreaker(cfg.CircuitBreaker, me, name),
+		config: bidderAdapterConfig{
+			DisableNativeEnrichment: adapterCfg.DisableNativeEnrichment,
+			MaxBidsPerSeat:          cfg.BidLimits.MaxBidsPerSeat,
+			MaxBidsPerImp:           cfg.BidLimits.MaxBidsPerImp,
+			MaxMarkupBytes:          cfg.BidLimits.MaxMarkupBytes,
+			Macros:                  newRequestMacros(adapterCfg.Macros),
+			DebugRedactor:           newDebugRedactor(cfg.Debug.RedactedFields),
+			GenerateBidIDs:          adapterCfg.GenerateBidIDs,
+			GzipRequests:            adapterCfg.GzipRequests,
+			GzipMinBodyBytes:        cfg.RequestCompression.MinBodyBytes,
+			PriceDecimals:           cfg.CurrencyConverter.PriceDecimals,
+			SplitRequestDeadline:    adapterCfg.SplitRequestDeadline,
+			RequestSampler:          newRequestSampler(name, adapterCfg.LogRequestSampleRate),
+			CallSampler:             newCallSampler(name, adapterCfg.SampleCallRate),
+			ResponseCache:           newResponseCache(adapterCfg.ResponseCache, name),
+			NoContentIsNoBid:        adapterCfg.NoContentIsNoBid,
+			WarnOnEmptyResponse:     adapterCfg.WarnOnEmptyResponse,
+			BidExpiration:           cfg.BidExpiration,
+			BidExtAllowlist:         newBidExtAllowlist(adapterCfg.BidExtAllowlist),
+			SyntheticLatency:        syntheticLatency(cfg, adapterCfg),
+			ReconcileBidTypes:       adapterCfg.ReconcileBidTypes,
+			RequestIDHeader:         cfg.RequestIDHeader,
+			DefaultHeaders:          newDefaultHeaders(cfg.BidderRequestHeaders),
+		},
+	}
+}
+
+// bidderTLSConfig loads the Bidder's client certificate and CA pool. It returns nil if the Bidder doesn't need its own TLS setup.
+func bidderTLSConfig(cfg config.TLSClient) (*tls.Config, error) {
+	if cfg == (config.TLSClient{}) {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.CAFile != "" {
+		caPEM, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA file: %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("CA file %s has no PEM certificates", cfg.CAFile)
+		}
+	}
+	return tlsConfig, nil
+}
+
+// bidderClient returns the shared client, unless the Bidder's config asks for its own connection pool or TLS setup.
+// Settings which the Bidder leaves at 0 are taken from the host's client config, and so are the trusted CAs
+// if tlsConfig doesn't have any.
+func bidderClient(shared *http.Client, hostCfg config.HTTPClient, bidderCfg config.HTTPClient, tlsConfig *tls.Config) *http.Client {
+	if bidderCfg == (config.HTTPClient{}) && tlsConfig == nil {
+		return shared
+	}
+	if bidderCfg.MaxIdleConns == 0 {
+		bidderCfg.MaxIdleConns = hostCfg.MaxIdleConns
+	}
+	if bidderCfg.MaxIdleConnsPerHost == 0 {
+		bidderCfg.MaxIdleConnsPerHost = hostCfg.MaxIdleConnsPerHost
+	}
+	if bidderCfg.IdleConnTimeout == 0 {
+		bidderCfg.IdleConnTimeout = hostCfg.IdleConnTimeout
+	}
+	if bidderCfg.ConnectTimeoutMs == 0 {
+		bidderCfg.ConnectTimeoutMs = hostCfg.ConnectTimeoutMs
+	}
+	if bidderCfg.TLSHandshakeTimeoutMs == 0 {
+		bidderCfg.TLSHandshakeTimeoutMs = hostCfg.TLSHandshakeTimeoutMs
+	}
+	if bidderCfg.ResponseHeaderTimeoutMs == 0 {
+		bidderCfg.ResponseHeaderTimeoutMs = hostCfg.ResponseHeaderTimeoutMs
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        bidderCfg.MaxIdleConns,
+		MaxIdleConnsPerHost: bidderCfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(bidderCfg.IdleConnTimeout) * time.Second,
+		DialContext: (&net.Dialer{
+			Timeout: time.Duration(bidderCfg.ConnectTimeoutMs) * time.Millisecond,
+		}).DialContext,
+		TLSHandshakeTimeout:   time.Duration(bidderCfg.TLSHandshakeTimeoutMs) * time.Millisecond,
+		ResponseHeaderTimeout: time.Duration(bidderCfg.ResponseHeaderTimeoutMs) * time.Millisecond,
 	}
+	client := &http.Client{
+		Transport: transport,
+	}
+	if shared != nil {
+		client.Timeout = shared.Timeout
+		// Keep the shared client's TLS setup, so that the Bidder's endpoint is trusted just as before.
+		if sharedTransport, ok := shared.Transport.(*http.Transport); ok {
+			transport.TLSClientConfig = sharedTransport.TLSClientConfig
+			transport.Proxy = sharedTransport.Proxy
+		}
+	}
+	if tlsConfig != nil {
+		// Without a CA file of its own, the Bidder's endpoint is trusted just like the others.
+		if tlsConfig.RootCAs == nil && transport.TLSClientConfig != nil {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.RootCAs = transport.TLSClientConfig.RootCAs
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return client
+}
+
+// withDefaultHeader returns a copy of the request with the header set, unless the Bidder already set it.
+// The headers are copied, since the Bidder may share them between requests.
+func withDefaultHeader(req *adapters.RequestData, name string, value string) *adapters.RequestData {
+	if req == nil || req.Headers.Get(name) != "" {
+		return req
+	}
+	withHeader := *req
+	withHeader.Headers = make(http.Header, len(req.Headers)+1)
+	for key, values := range req.Headers {
+		withHeader.Headers[key] = values
+	}
+	withHeader.Headers.Set(name, value)
+	return &withHeader
+}
+
+// syntheticLatency ignores the Bidder's synthetic latency unless chaos testing is enabled, even if validation was skipped.
+func syntheticLatency(cfg *config.Configuration, adapterCfg config.Adapter) time.Duration {
+	if !cfg.EnableChaosTesting || adapterCfg.SyntheticLatencyMs <= 0 {
+		return 0
+	}
+	return time.Duration(adapterCfg.SyntheticLatencyMs) * time.Millisecond
+}
+
+// withRedirectPolicy returns a copy of the client which only follows the redirects allowed by the Bidder's config.
+// The copy shares the client's Transport, and therefore its connections.
+func withRedirectPolicy(client *http.Client, cfg config.Redirects) *http.Client {
+	if client == nil || cfg == (config.Redirects{}) {
+		return client
+	}
+	limited := *client
+	limited.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if cfg.Disabled {
+			return fmt.Errorf("the bidder redirected the request to %s, but redirects are disabled for it", req.URL.Host)
+		}
+		if len(via) > cfg.Max {
+			return fmt.Errorf("the bidder redirected the request to %s after %d redirects, but at most %d are allowed for it", req.URL.Host, cfg.Max, cfg.Max)
+		}
+		return nil
+	}
+	return &limited
 }
 
 type bidderAdapter struct {
 	Bidder adapters.Bidder
-	Client *http.Client
+	// BidderName is the core bidder's name, which metrics are recorded under. requestBid may be called with an alias.
+	BidderName openrtb_ext.BidderName
+	Client     *http.Client
+	me         pbsmetrics.MetricsEngine
+	// breaker is nil unless circuit breaking is enabled in the config.
+	breaker *circuitBreaker
+	config  bidderAdapterConfig
+}
+
+// bidderAdapterConfig holds the parts of the host config which change how a single Bidder is handled.
+type bidderAdapterConfig struct {
+	DisableNativeEnrichment bool
+	// MaxBidsPerSeat and MaxBidsPerImp limit the bids kept from each call to requestBid. 0 means no limit.
+	MaxBidsPerSeat int
+	MaxBidsPerImp  int
+	// MaxMarkupBytes drops bids with longer markup. 0 means no limit.
+	MaxMarkupBytes int
+	// Macros is nil if the Bidder has no macros configured.
+	Macros *requestMacros
+	// DebugRedactor is nil unless some fields should be masked in the debug output.
+	DebugRedactor *debugRedactor
+	// GenerateBidIDs gives a random ID to each bid which the Bidder left without one.
+	GenerateBidIDs bool
+	// PriceDecimals rounds the adjusted and converted bid prices. 0 means no rounding.
+	PriceDecimals int
+	// GzipRequests compresses request bodies which are longer than GzipMinBodyBytes.
+	GzipRequests     bool
+	GzipMinBodyBytes int
+	// SplitRequestDeadline divides the time left before the deadline between the Bidder's parallel requests.
+	SplitRequestDeadline bool
+	// RequestSampler is nil unless some of the Bidder's requests should be logged.
+	RequestSampler *requestSampler
+	// CallSampler is nil unless some of the Bidder's calls should be sent to the HTTPCallSink.
+	CallSampler *callSampler
+	// ResponseCache is nil unless the Bidder's responses should be cached.
+	ResponseCache *responseCache
+	// NoContentIsNoBid skips MakeBids for 204 responses, since they can't contain any bids.
+	NoContentIsNoBid bool
+	// WarnOnEmptyResponse turns 200 responses with blank bodies into warnings, instead of passing them to MakeBids.
+	WarnOnEmptyResponse bool
+	// BidExpiration limits the exp of the Bidder's bids. Its zero value leaves them alone.
+	BidExpiration config.BidExpirations
+	// BidExtAllowlist is nil unless the Bidder's bid.ext should be pruned down to these keys.
+	BidExtAllowlist map[string]bool
+	// SyntheticLatency delays each request before it's sent. It's only non-zero when chaos testing is enabled.
+	SyntheticLatency time.Duration
+	// ReconcileBidTypes retypes the bids whose type doesn't fit their imp or markup.
+	ReconcileBidTypes bool
+	// RequestIDHeader is the header which carries the request ID to the Bidder. It's empty if none is sent.
+	RequestIDHeader string
+	// DefaultHeaders is nil unless some headers should be added to all of the Bidder's requests.
+	DefaultHeaders *defaultHeaders
 }
 
-func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
+func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (*pbsOrtbSeatBid, []error) {
 	reqData, errs := bidder.Bidder.MakeRequests(request, reqInfo)
 
 	if len(reqData) == 0 {
@@ -105,15 +382,32 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 		return nil, errs
 	}
 
+	if bidder.config.RequestIDHeader != "" && request.ID != "" {
+		for i := range reqData {
+			reqData[i] = withDefaultHeader(reqData[i], bidder.config.RequestIDHeader, request.ID)
+		}
+	}
+	if bidder.config.DefaultHeaders != nil {
+		for i := range reqData {
+			reqData[i] = bidder.config.DefaultHeaders.apply(reqData[i], request.ID, name)
+		}
+	}
+
 	// Make any HTTP requests in parallel.
 	// If the bidder only needs to make one, save some cycles by just using the current one.
 	responseChannel := make(chan *httpCallInfo, len(reqData))
 	if len(reqData) == 1 {
-		responseChannel <- bidder.doRequest(ctx, reqData[0])
+		responseChannel <- bidder.doLimitedRequest(ctx, reqData[0], request.Test == 1, options.callLimiter)
 	} else {
+		requestCtx := ctx
+		if bidder.config.SplitRequestDeadline {
+			var cancel context.CancelFunc
+			requestCtx, cancel = splitDeadline(ctx, len(reqData))
+			defer cancel()
+		}
 		for _, oneReqData := range reqData {
 			go func(data *adapters.RequestData) {
-				responseChannel <- bidder.doRequest(ctx, data)
+				responseChannel <- bidder.doLimitedRequest(requestCtx, data, request.Test == 1, options.callLimiter)
 			}(oneReqData) // Method arg avoids a race condition on oneReqData
 		}
 	}
@@ -127,11 +421,27 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 
 	// If the bidder made multiple requests, we still want them to enter as many bids as possible...
 	// even if the timeout occurs sometime halfway through.
+	var succeeded, failed, timedOut int
 	for i := 0; i < len(reqData); i++ {
 		httpInfo := <-responseChannel
+		if httpInfo.err == nil {
+			succeeded++
+		} else {
+			failed++
+			if _, ok := httpInfo.err.(*errortypes.Timeout); ok {
+				timedOut++
+			}
+		}
 		// If this is a test bid, capture debugging info from the requests.
+		var httpCall *int
 		if request.Test == 1 {
-			seatBid.httpCalls = append(seatBid.httpCalls, makeExt(httpInfo))
+			seatBid.httpCalls = append(seatBid.httpCalls, bidder.config.DebugRedactor.redact(makeExt(httpInfo)))
+			callIndex := len(seatBid.httpCalls) - 1
+			httpCall = &callIndex
+		}
+
+		if httpInfo.err == nil && bidder.config.NoContentIsNoBid && httpInfo.response.StatusCode == http.StatusNoContent {
+			continue
 		}
 
 		if httpInfo.err == nil {
@@ -147,20 +457,48 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 					request.Cur = []string{defaultCurrency}
 				}
 
-				// Try to get a conversion rate
-				// Try to get the first currency from request.cur having a match in the rate converter,
-				// and use it as currency
+				if bidder.config.GenerateBidIDs {
+					numGenerated, err := fillMissingBidIDs(bidResponse.Bids)
+					if err != nil {
+						errs = append(errs, err)
+					}
+					if numGenerated > 0 {
+						bidder.me.RecordAdapterGeneratedBidIDs(bidder.BidderName, numGenerated)
+					}
+				}
+
+				// The bids are made in the first currency of request.cur which they can be converted to.
+				// If they can't be converted to any of them, the fallback currency is tried last.
 				var conversionRate float64
 				var err error
-				for _, bidReqCur := range request.Cur {
-					if conversionRate, err = conversions.GetRate(bidResponse.Currency, bidReqCur); err == nil {
+				var selection pbsmetrics.CurrencySelection
+				for i, bidReqCur := range request.Cur {
+					if conversionRate, err = getRate(conversions, bidResponse.Currency, bidReqCur); err == nil {
 						seatBid.currency = bidReqCur
+						selection = pbsmetrics.CurrencySelectionFirst
+						if i > 0 {
+							selection = pbsmetrics.CurrencySelectionOther
+						}
 						break
 					}
 				}
+				if err != nil && options.fallbackCurrency != "" {
+					if fallbackRate, fallbackErr := getRate(conversions, bidResponse.Currency, options.fallbackCurrency); fallbackErr == nil {
+						conversionRate, err = fallbackRate, nil
+						seatBid.currency = options.fallbackCurrency
+						selection = pbsmetrics.CurrencySelectionFallback
+					}
+				}
+				if err == nil {
+					bidder.me.RecordAdapterCurrencySelection(bidder.BidderName, selection)
+				}
+
+				if bidder.config.ReconcileBidTypes {
+					errs = append(errs, reconcileBidTypes(bidResponse.Bids, request)...)
+				}
 
 				// Only do this for request from mobile app
-				if request.App != nil {
+				if request.App != nil && !bidder.config.DisableNativeEnrichment {
 					for i := 0; i < len(bidResponse.Bids); i++ {
 						if bidResponse.Bids[i].BidType == openrtb_ext.BidTypeNative {
 							nativeMarkup, moreErrs := addNativeTypes(bidResponse.Bids[i].Bid, request)
@@ -181,29 +519,345 @@ func (bidder *bidderAdapter) requestBid(ctx context.Context, request *openrtb.Bi
 				if err == nil {
 					// Conversion rate found, using it for conversion
 					for i := 0; i < len(bidResponse.Bids); i++ {
+						bidRate := conversionRate
+						bidCur := bidResponse.Currency
+						if bidResponse.Bids[i].Currency != "" && bidResponse.Bids[i].Currency != bidResponse.Currency {
+							// This bid overrides the response currency, so it needs its own rate.
+							bidCur = bidResponse.Bids[i].Currency
+							var bidErr error
+							if bidRate, bidErr = getRate(conversions, bidCur, seatBid.currency); bidErr != nil {
+								errs = append(errs, bidErr)
+								bidder.me.RecordAdapterBidsDropped(bidder.BidderName, pbsmetrics.BidDropReasonCurrencyConversion, 1)
+								continue
+							}
+						}
+						if request.Test == 1 && bidCur != seatBid.currency {
+							seatBid.recordConversion(bidCur, seatBid.currency, bidRate)
+						}
 						if bidResponse.Bids[i].Bid != nil {
-							bidResponse.Bids[i].Bid.Price = bidResponse.Bids[i].Bid.Price * bidAdjustment * conversionRate
+							// Native enrichment has already rewritten the markup, so this is the size which will be sent on.
+							if markupSize := len(bidResponse.Bids[i].Bid.AdM); markupSize > 0 {
+								bidder.me.RecordAdapterMarkupSize(bidder.BidderName, markupSize)
+								if bidder.config.MaxMarkupBytes > 0 && markupSize > bidder.config.MaxMarkupBytes {
+									bidder.me.RecordAdapterBidsDropped(bidder.BidderName, pbsmetrics.BidDropReasonMarkupTooLarge, 1)
+									errs = append(errs, &errortypes.Warning{
+										Message: fmt.Sprintf("Bid %s was dropped because its markup is %d bytes, which exceeds the limit of %d.", bidResponse.Bids[i].Bid.ID, markupSize, bidder.config.MaxMarkupBytes),
+									})
+									continue
+								}
+							}
+							bidResponse.Bids[i].Bid.Price = roundPrice(bidResponse.Bids[i].Bid.Price*bidAdjustment*bidRate, bidder.config.PriceDecimals)
+							if price := bidResponse.Bids[i].Bid.Price; math.IsNaN(price) || math.IsInf(price, 0) {
+								bidder.me.RecordAdapterBidsDropped(bidder.BidderName, pbsmetrics.BidDropReasonInvalidPrice, 1)
+								errs = append(errs, &errortypes.Warning{
+									Message: fmt.Sprintf("Bid %s was dropped because its price is not a finite number.", bidResponse.Bids[i].Bid.ID),
+								})
+								continue
+							}
+							if bidder.config.BidExtAllowlist != nil && len(bidResponse.Bids[i].Bid.Ext) > 0 {
+								var extErr error
+								if bidResponse.Bids[i].Bid.Ext, extErr = pruneBidExt(bidResponse.Bids[i].Bid.Ext, bidder.config.BidExtAllowlist); extErr != nil {
+									errs = append(errs, &errortypes.Warning{
+										Message: fmt.Sprintf("The ext of bid %s was removed because it couldn't be pruned: %v", bidResponse.Bids[i].Bid.ID, extErr),
+									})
+								}
+							}
 						}
-						seatBid.bids = append(seatBid.bids, &pbsOrtbBid{
+						pbsBid := &pbsOrtbBid{
 							bid:          bidResponse.Bids[i].Bid,
 							bidType:      bidResponse.Bids[i].BidType,
 							bidVideo:     bidResponse.Bids[i].BidVideo,
 							dealPriority: bidResponse.Bids[i].DealPriority,
-						})
+							seat:         bidResponse.Bids[i].Seat,
+							httpCall:     httpCall,
+						}
+						if options.bidCallback != nil && pbsBid.bid != nil {
+							if err := options.bidCallback(name, pbsBid); err != nil {
+								errs = append(errs, err)
+								bidder.me.RecordAdapterBidsDropped(bidder.BidderName, pbsmetrics.BidDropReasonRejected, 1)
+								continue
+							}
+						}
+						seatBid.bids = append(seatBid.bids, pbsBid)
 					}
 				} else {
 					// If no conversions found, do not handle the bid
 					errs = append(errs, err)
+					bidder.me.RecordAdapterBidsDropped(bidder.BidderName, pbsmetrics.BidDropReasonCurrencyConversion, len(bidResponse.Bids))
 				}
 			}
 		} else {
 			errs = append(errs, httpInfo.err)
 		}
 	}
+	seatBid.partial = succeeded > 0 && failed > 0
+	seatBid.partialTimeout = succeeded > 0 && timedOut > 0
+
+	if options.allowedImps != nil {
+		numDropped, moreErrs := removeDisallowedImpBids(seatBid, options.allowedImps)
+		if numDropped > 0 {
+			bidder.me.RecordAdapterBidsDropped(bidder.BidderName, pbsmetrics.BidDropReasonImpNotAllowed, numDropped)
+			errs = append(errs, moreErrs...)
+		}
+	}
+
+	if options.adomainFilter != nil {
+		numDropped, moreErrs := options.adomainFilter.apply(seatBid)
+		for reason, count := range numDropped {
+			bidder.me.RecordAdapterBidsDropped(bidder.BidderName, reason, count)
+		}
+		errs = append(errs, moreErrs...)
+	}
+
+	if bidder.config.BidExpiration != (config.BidExpirations{}) {
+		errs = append(errs, normalizeBidExpirations(seatBid.bids, bidder.config.BidExpiration)...)
+	}
+
+	if bidder.config.MaxBidsPerSeat > 0 || bidder.config.MaxBidsPerImp > 0 {
+		var numDropped int
+		seatBid.bids, numDropped = capBids(seatBid.bids, bidder.config.MaxBidsPerSeat, bidder.config.MaxBidsPerImp)
+		if numDropped > 0 {
+			bidder.me.RecordAdapterBidsDropped(bidder.BidderName, pbsmetrics.BidDropReasonBidLimit, numDropped)
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("%d bids were dropped because the bidder exceeded the limit of %d bids per seat and %d bids per imp (0 is unlimited). The highest-priced bids were kept.", numDropped, bidder.config.MaxBidsPerSeat, bidder.config.MaxBidsPerImp),
+			})
+		}
+	}
 
 	return seatBid, errs
 }
 
+// getRate returns 1 without a lookup if the currencies match, so that bids which need no conversion
+// are never dropped because the rates don't know their currency.
+func getRate(conversions currencies.Conversions, from string, to string) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return 1, nil
+	}
+	return conversions.GetRate(from, to)
+}
+
+// splitDeadline returns a context which expires after 1/n of the time left before ctx's deadline.
+// If ctx has no deadline, it's returned unchanged.
+func splitDeadline(ctx context.Context, n int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || n <= 1 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(n))
+}
+
+// normalizeBidExpirations gives a default exp to bids without one, and clamps the rest to the limits
+// configured for their media type. It warns about each bid whose exp was clamped.
+func normalizeBidExpirations(bids []*pbsOrtbBid, cfg config.BidExpirations) []error {
+	var errs []error
+	for _, bid := range bids {
+		if bid.bid == nil {
+			continue
+		}
+		var limits config.BidExpiration
+		switch bid.bidType {
+		case openrtb_ext.BidTypeBanner:
+			limits = cfg.Banner
+		case openrtb_ext.BidTypeVideo:
+			limits = cfg.Video
+		case openrtb_ext.BidTypeAudio:
+			limits = cfg.Audio
+		case openrtb_ext.BidTypeNative:
+			limits = cfg.Native
+		}
+
+		if bid.bid.Exp == 0 {
+			bid.bid.Exp = int64(limits.DefaultSeconds)
+			continue
+		}
+		exp := bid.bid.Exp
+		if limits.MaxSeconds > 0 && exp > int64(limits.MaxSeconds) {
+			exp = int64(limits.MaxSeconds)
+		}
+		if limits.MinSeconds > 0 && exp < int64(limits.MinSeconds) {
+			exp = int64(limits.MinSeconds)
+		}
+		if exp != bid.bid.Exp {
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("Bid %s had exp %d, which was changed to %d to fit the limits for %s bids.", bid.bid.ID, bid.bid.Exp, exp, bid.bidType),
+			})
+			bid.bid.Exp = exp
+		}
+	}
+	return errs
+}
+
+// removeDisallowedImpBids drops the bids for Imps which aren't in allowedImps, and warns about each one.
+// Bids with no openrtb.Bid are left for the validation in bidder_validate_bids.go to report.
+func removeDisallowedImpBids(seatBid *pbsOrtbSeatBid, allowedImps map[string]bool) (int, []error) {
+	var errs []error
+	kept := seatBid.bids[:0]
+	for _, bid := range seatBid.bids {
+		if bid.bid == nil || allowedImps[bid.bid.ImpID] {
+			kept = append(kept, bid)
+			continue
+		}
+		errs = append(errs, &errortypes.Warning{
+			Message: fmt.Sprintf("Bid %s was dropped because this bidder isn't allowed to bid on imp %s in this request.", bid.bid.ID, bid.bid.ImpID),
+		})
+	}
+	seatBid.bids = kept
+	return len(errs), errs
+}
+
+// notifyWin sends the Bidder a win notification in the background, if it implements adapters.WinNotifier.
+func (bidder *bidderAdapter) notifyWin(bid *pbsOrtbBid) {
+	if winNotifier, ok := bidder.Bidder.(adapters.WinNotifier); ok {
+		go bidder.doWinNotification(winNotifier, bid)
+	}
+}
+
+func (bidder *bidderAdapter) doWinNotification(winNotifier adapters.WinNotifier, bid *pbsOrtbBid) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	notification, errs := winNotifier.MakeWinNotification(&adapters.TypedBid{
+		Bid:          bid.bid,
+		BidType:      bid.bidType,
+		BidVideo:     bid.bidVideo,
+		DealPriority: bid.dealPriority,
+		Seat:         bid.seat,
+	})
+	if notification == nil && len(errs) == 0 {
+		return
+	}
+
+	delivered := false
+	if notification != nil && len(errs) == 0 {
+		httpReq, err := http.NewRequest(notification.Method, notification.Uri, bytes.NewBuffer(notification.Body))
+		if err == nil {
+			httpReq.Header = notification.Headers
+			if httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq); err == nil {
+				drainAndClose(httpResp.Body)
+				delivered = httpResp.StatusCode >= 200 && httpResp.StatusCode < 300
+			}
+		}
+	}
+	bidder.me.RecordAdapterWinNotification(bidder.BidderName, delivered)
+}
+
+// newBidExtAllowlist returns nil if there are no keys, so that bid.ext is left alone.
+func newBidExtAllowlist(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+	allowlist := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		allowlist[key] = true
+	}
+	return allowlist
+}
+
+// pruneBidExt removes the top-level keys of a bid.ext which aren't in the allowlist. It returns nil if none are left.
+// An ext which isn't a JSON object can't be pruned, so it's removed entirely and an error is returned.
+func pruneBidExt(ext json.RawMessage, allowlist map[string]bool) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(ext, &fields); err != nil {
+		return nil, err
+	}
+	for key := range fields {
+		if !allowlist[key] {
+			delete(fields, key)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
+
+// roundPrice rounds the price to the given number of decimal places. 0 leaves it unchanged.
+func roundPrice(price float64, decimals int) float64 {
+	if decimals <= 0 {
+		return price
+	}
+	scale := math.Pow10(decimals)
+	return math.Round(price*scale) / scale
+}
+
+// fillMissingBidIDs sets a UUID as the ID of every bid which doesn't have one, and returns how many it set.
+func fillMissingBidIDs(bids []*adapters.TypedBid) (int, error) {
+	numGenerated := 0
+	for _, typedBid := range bids {
+		if typedBid.Bid == nil || typedBid.Bid.ID != "" {
+			continue
+		}
+		bidID, err := uuid.NewV4()
+		if err != nil {
+			return numGenerated, fmt.Errorf("Failed to generate an ID for a bid on imp %s: %v", typedBid.Bid.ImpID, err)
+		}
+		typedBid.Bid.ID = bidID.String()
+		numGenerated++
+	}
+	return numGenerated, nil
+}
+
+// gzipBody compresses an outgoing request body. The uncompressed body is still the one shown in debug output.
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// capBids keeps at most maxPerImp bids on each imp, and at most maxPerSeat bids in total, preferring the highest prices.
+// A limit of 0 means unlimited. The bids which are kept stay in their original order.
+func capBids(bids []*pbsOrtbBid, maxPerSeat int, maxPerImp int) ([]*pbsOrtbBid, int) {
+	byPrice := make([]int, len(bids))
+	for i := range byPrice {
+		byPrice[i] = i
+	}
+	sort.SliceStable(byPrice, func(i, j int) bool {
+		return bidPrice(bids[byPrice[i]]) > bidPrice(bids[byPrice[j]])
+	})
+
+	keep := make([]bool, len(bids))
+	kept := 0
+	keptPerImp := make(map[string]int)
+	for _, index := range byPrice {
+		if maxPerSeat > 0 && kept >= maxPerSeat {
+			break
+		}
+		var impID string
+		if bids[index].bid != nil {
+			impID = bids[index].bid.ImpID
+		}
+		if maxPerImp > 0 && keptPerImp[impID] >= maxPerImp {
+			continue
+		}
+		keep[index] = true
+		keptPerImp[impID]++
+		kept++
+	}
+
+	if kept == len(bids) {
+		return bids, 0
+	}
+	cappedBids := make([]*pbsOrtbBid, 0, kept)
+	for i, bid := range bids {
+		if keep[i] {
+			cappedBids = append(cappedBids, bid)
+		}
+	}
+	return cappedBids, len(bids) - kept
+}
+
+func bidPrice(bid *pbsOrtbBid) float64 {
+	if bid.bid == nil {
+		return 0
+	}
+	return bid.bid.Price
+}
+
 func addNativeTypes(bid *openrtb.Bid, request *openrtb.BidRequest) (*nativeResponse.Response, []error) {
 	var errs []error
 	var nativeMarkup *nativeResponse.Response
@@ -218,8 +872,8 @@ func addNativeTypes(bid *openrtb.Bid, request *openrtb.BidRequest) (*nativeRespo
 		return nil, errs
 	}
 
-	var nativePayload nativeRequests.Request
-	if err := json.Unmarshal(json.RawMessage((*nativeImp).Request), &nativePayload); err != nil {
+	nativePayload, err := parseNativeRequest(nativeImp.Request)
+	if err != nil {
 		errs = append(errs, err)
 	}
 
@@ -227,11 +881,29 @@ func addNativeTypes(bid *openrtb.Bid, request *openrtb.BidRequest) (*nativeRespo
 		if err := setAssetTypes(asset, nativePayload); err != nil {
 			errs = append(errs, err)
 		}
+		if err := checkImageSize(bid.ID, asset, nativePayload); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	return nativeMarkup, errs
 }
 
+// checkImageSize warns about an image asset which has no dimensions, unless the request pinned its exact size.
+// The publisher can't lay out such an image before it loads. The bid is kept either way.
+func checkImageSize(bidID string, asset nativeResponse.Asset, nativePayload nativeRequests.Request) error {
+	if asset.Img == nil || (asset.Img.W > 0 && asset.Img.H > 0) {
+		return nil
+	}
+	if requestAsset, err := getAssetByID(asset.ID, nativePayload.Assets); err == nil && requestAsset.Img != nil &&
+		requestAsset.Img.W > 0 && requestAsset.Img.H > 0 {
+		return nil
+	}
+	return &errortypes.Warning{
+		Message: fmt.Sprintf("Bid %s has a native image asset with ID:%d whose size is missing, and the request didn't set one", bidID, asset.ID),
+	}
+}
+
 func setAssetTypes(asset nativeResponse.Asset, nativePayload nativeRequests.Request) error {
 	if asset.Img != nil {
 		if tempAsset, err := getAssetByID(asset.ID, nativePayload.Assets); err == nil {
@@ -263,13 +935,45 @@ func setAssetTypes(asset nativeResponse.Asset, nativePayload nativeRequests.Requ
 	return nil
 }
 
+// getNativeImpByImpID finds the native object of the bid's imp. Multi-format imps are sometimes split into
+// several imps with the same ID, so every imp with a matching ID is checked before giving up.
 func getNativeImpByImpID(impID string, request *openrtb.BidRequest) (*openrtb.Native, error) {
+	impFound := false
 	for _, impInRequest := range request.Imp {
-		if impInRequest.ID == impID && impInRequest.Native != nil {
+		if impInRequest.ID != impID {
+			continue
+		}
+		impFound = true
+		if impInRequest.Native != nil {
 			return impInRequest.Native, nil
 		}
 	}
-	return nil, errors.New("Could not find native imp")
+	if impFound {
+		return nil, &errortypes.Warning{
+			Message: fmt.Sprintf("Could not find native imp: imp %s has no native object, so the types of the native assets in its bids weren't filled in", impID),
+		}
+	}
+	return nil, &errortypes.Warning{
+		Message: fmt.Sprintf("Could not find native imp: no imp has ID %s, so the types of the native assets in its bids weren't filled in", impID),
+	}
+}
+
+// parseNativeRequest reads the native.request of an imp. Native 1.0 payloads are wrapped in a "native" object,
+// while 1.1 and later are not, so both forms are accepted.
+func parseNativeRequest(request string) (nativeRequests.Request, error) {
+	var wrapped struct {
+		Native *nativeRequests.Request `json:"native"`
+	}
+	if err := json.Unmarshal(json.RawMessage(request), &wrapped); err != nil {
+		return nativeRequests.Request{}, err
+	}
+	if wrapped.Native != nil {
+		return *wrapped.Native, nil
+	}
+
+	var nativePayload nativeRequests.Request
+	err := json.Unmarshal(json.RawMessage(request), &nativePayload)
+	return nativePayload, err
 }
 
 func getAssetByID(id int64, assets []nativeRequests.Asset) (nativeRequests.Asset, error) {
@@ -281,6 +985,12 @@ func getAssetByID(id int64, assets []nativeRequests.Asset) (nativeRequests.Asset
 	return nativeRequests.Asset{}, fmt.Errorf("Unable to find asset with ID:%d in the request", id)
 }
 
+// sentBytes returns the exact request body which was sent to the bidder, or nil if the request couldn't be built.
+// Use it rather than request.Body for anything which must match the bytes on the wire, like signatures.
+func (info *httpCallInfo) sentBytes() []byte {
+	return info.sentBody
+}
+
 // makeExt transforms information about the HTTP call into the contract class for the PBS response.
 func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 	if httpInfo.err == nil {
@@ -289,31 +999,138 @@ func makeExt(httpInfo *httpCallInfo) *openrtb_ext.ExtHttpCall {
 			RequestBody:  string(httpInfo.request.Body),
 			ResponseBody: string(httpInfo.response.Body),
 			Status:       httpInfo.response.StatusCode,
+			Connection:   httpInfo.connection,
+			Cached:       httpInfo.cached,
+			OriginalUri:  httpInfo.originalURI,
 		}
 	} else if httpInfo.request == nil {
 		return &openrtb_ext.ExtHttpCall{}
+	} else if httpInfo.partialResponse != nil {
+		return &openrtb_ext.ExtHttpCall{
+			Uri:             httpInfo.request.Uri,
+			RequestBody:     string(httpInfo.request.Body),
+			ResponseBody:    string(httpInfo.partialResponse.Body),
+			Status:          httpInfo.partialResponse.StatusCode,
+			PartialResponse: true,
+			Connection:      httpInfo.connection,
+			OriginalUri:     httpInfo.originalURI,
+		}
 	} else {
 		return &openrtb_ext.ExtHttpCall{
 			Uri:         httpInfo.request.Uri,
 			RequestBody: string(httpInfo.request.Body),
+			Connection:  httpInfo.connection,
+			OriginalUri: httpInfo.originalURI,
+		}
+	}
+}
+
+// doLimitedRequest waits for the limiter to allow another call before calling doRequest.
+func (bidder *bidderAdapter) doLimitedRequest(ctx context.Context, req *adapters.RequestData, traceConnection bool, limiter *callLimiter) *httpCallInfo {
+	if !limiter.acquire(ctx) {
+		return &httpCallInfo{
+			request: req,
+			err:     &errortypes.Timeout{Message: "The timeout expired while waiting for the auction's other bidder calls to finish."},
 		}
 	}
+	defer limiter.release()
+	return bidder.doRequest(ctx, req, traceConnection)
 }
 
 // doRequest makes a request, handles the response, and returns the data needed by the
-// Bidder interface.
-func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData) *httpCallInfo {
-	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(req.Body))
-	if err != nil {
+// Bidder interface. If traceConnection is true, the result also describes how the connection was set up.
+//
+// If the call is sampled, it's also sent to the HTTPCallSink.
+func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.RequestData, traceConnection bool) *httpCallInfo {
+	httpInfo := bidder.doUnsampledRequest(ctx, req, traceConnection)
+	bidder.config.CallSampler.sample(httpInfo)
+	return httpInfo
+}
+
+func (bidder *bidderAdapter) doUnsampledRequest(ctx context.Context, req *adapters.RequestData, traceConnection bool) *httpCallInfo {
+	if !bidder.breaker.allow() {
 		return &httpCallInfo{
 			request: req,
-			err:     err,
+			err:     &errortypes.BidderUnavailable{Message: "The bidder was not called because its endpoint has been failing. It will be tried again after a cooldown."},
+		}
+	}
+
+	req = bidder.config.Macros.apply(req)
+	var originalURI string
+	if rewrite := uriRewriterFor(bidder.BidderName); rewrite != nil {
+		uri, err := rewrite(req.Uri)
+		if err != nil {
+			return &httpCallInfo{
+				request: req,
+				err:     &errortypes.FailedToRequestBids{Message: fmt.Sprintf("The request URI couldn't be rewritten: %v", err)},
+			}
+		}
+		if uri != req.Uri {
+			originalURI = req.Uri
+			rewritten := *req
+			rewritten.Uri = uri
+			req = &rewritten
+		}
+	}
+	if cachedResp := bidder.config.ResponseCache.get(req); cachedResp != nil {
+		return &httpCallInfo{
+			request:     req,
+			originalURI: originalURI,
+			response:    cachedResp,
+			cached:      true,
+		}
+	}
+	bidder.config.RequestSampler.sample(req)
+	body := req.Body
+	gzipped := bidder.config.GzipRequests && len(body) > bidder.config.GzipMinBodyBytes
+	if gzipped {
+		var err error
+		if body, err = gzipBody(body); err != nil {
+			return &httpCallInfo{
+				request:     req,
+				originalURI: originalURI,
+				err:         err,
+			}
+		}
+	}
+
+	httpReq, err := http.NewRequest(req.Method, req.Uri, bytes.NewBuffer(body))
+	if err != nil {
+		return &httpCallInfo{
+			request:     req,
+			originalURI: originalURI,
+			err:         err,
 		}
 	}
 	httpReq.Header = req.Headers
+	if gzipped {
+		// Copy the headers, since the Bidder may share them between requests.
+		httpReq.Header = make(http.Header, len(req.Headers)+1)
+		for key, values := range req.Headers {
+			httpReq.Header[key] = values
+		}
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+
+	var connTrace *connectionTrace
+	if traceConnection {
+		ctx, connTrace = withConnectionTrace(ctx)
+	}
+
+	if bidder.config.SyntheticLatency > 0 {
+		// Running out the clock here sends the call down the same path as a slow Bidder.
+		select {
+		case <-time.After(bidder.config.SyntheticLatency):
+		case <-ctx.Done():
+		}
+	}
 
 	httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq)
 	if err != nil {
+		// If the auction was cancelled, that says nothing about the health of the bidder.
+		if err != context.Canceled {
+			bidder.breaker.recordFailure()
+		}
 		if err == context.DeadlineExceeded {
 			err = &errortypes.Timeout{Message: err.Error()}
 			if tb, ok := bidder.Bidder.(adapters.TimeoutBidder); ok {
@@ -326,34 +1143,121 @@ func (bidder *bidderAdapter) doRequest(ctx context.Context, req *adapters.Reques
 
 		}
 		return &httpCallInfo{
-			request: req,
-			err:     err,
+			request:     req,
+			originalURI: originalURI,
+			sentBody:    body,
+			connection:  connTrace.result(),
+			err:         err,
 		}
 	}
 
+	defer httpResp.Body.Close()
 	respBody, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = &errortypes.Timeout{Message: fmt.Sprintf("The timeout expired while reading the response: %v", err)}
+		}
+		// Keep whatever did arrive, so that debug output shows whether the bidder had started to respond.
 		return &httpCallInfo{
-			request: req,
-			err:     err,
+			request:     req,
+			originalURI: originalURI,
+			sentBody:    body,
+			partialResponse: &adapters.ResponseData{
+				StatusCode: httpResp.StatusCode,
+				Body:       respBody,
+				Headers:    httpResp.Header,
+			},
+			connection: connTrace.result(),
+			err:        err,
 		}
 	}
-	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode >= 500 {
+		bidder.breaker.recordFailure()
+	} else {
+		bidder.breaker.recordSuccess()
+	}
 
 	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 400 {
 		err = &errortypes.BadServerResponse{
 			Message: fmt.Sprintf("Server responded with failure status: %d. Set request.test = 1 for debugging info.", httpResp.StatusCode),
+			Subcode: statusSubcode(httpResp.StatusCode),
 		}
+	} else if decodedBody, decodeErr := decodeToUTF8(respBody, httpResp.Header.Get("Content-Type")); decodeErr != nil {
+		err = decodeErr
+	} else {
+		respBody = decodedBody
+		if bidder.config.WarnOnEmptyResponse && httpResp.StatusCode == http.StatusOK && len(bytes.TrimSpace(respBody)) == 0 {
+			err = &errortypes.Warning{
+				Message: "Server responded with 200 and an empty body, which this bidder isn't expected to send for a no-bid.",
+			}
+		}
+	}
+
+	response := &adapters.ResponseData{
+		StatusCode: httpResp.StatusCode,
+		Body:       respBody,
+		Headers:    httpResp.Header,
+	}
+	if err == nil && httpResp.StatusCode < 300 {
+		bidder.config.ResponseCache.set(req, response)
 	}
 
 	return &httpCallInfo{
-		request: req,
-		response: &adapters.ResponseData{
-			StatusCode: httpResp.StatusCode,
-			Body:       respBody,
-			Headers:    httpResp.Header,
-		},
-		err: err,
+		request:     req,
+		originalURI: originalURI,
+		sentBody:    body,
+		response:    response,
+		connection:  connTrace.result(),
+		err:         err,
+	}
+}
+
+// decodeToUTF8 transcodes the body from the charset named in the Content-Type header, so that Bidders can always
+// unmarshal UTF-8. Bodies without a charset are returned unchanged.
+func decodeToUTF8(body []byte, contentType string) ([]byte, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil || params["charset"] == "" {
+		return body, nil
+	}
+	encoding, err := htmlindex.Get(params["charset"])
+	if err != nil {
+		return body, &errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Server responded with unsupported charset %q.", params["charset"]),
+		}
+	}
+	if name, _ := htmlindex.Name(encoding); name == "utf-8" {
+		return body, nil
+	}
+	decoded, err := encoding.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, &errortypes.BadServerResponse{
+			Message: fmt.Sprintf("Server response could not be decoded from charset %q: %v", params["charset"], err),
+		}
+	}
+	return decoded, nil
+}
+
+// maxDrainBytes limits how much of an unwanted response body is read so that its connection can be reused.
+// Past that, it's cheaper to close the connection.
+const maxDrainBytes = 64 << 10
+
+// drainAndClose reads and discards the rest of a response body before closing it.
+// The HTTP client only returns a connection to the idle pool if its body was read to the end.
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
+
+// statusSubcode classifies an unsuccessful HTTP status for a BadServerResponse.
+func statusSubcode(status int) int {
+	switch {
+	case status >= 400 && status < 500:
+		return errortypes.ClientErrorStatusSubcode
+	case status >= 500:
+		return errortypes.ServerErrorStatusSubcode
+	default:
+		return errortypes.UnexpectedStatusSubcode
 	}
 }
 
@@ -365,7 +1269,9 @@ func (bidder *bidderAdapter) doTimeoutNotification(timeoutBidder adapters.Timeou
 		httpReq, err := http.NewRequest(toReq.Method, toReq.Uri, bytes.NewBuffer(toReq.Body))
 		if err == nil {
 			httpReq.Header = req.Headers
-			ctxhttp.Do(ctx, bidder.Client, httpReq)
+			if httpResp, err := ctxhttp.Do(ctx, bidder.Client, httpReq); err == nil {
+				drainAndClose(httpResp.Body)
+			}
 			// No validation yet on sending notifications
 		}
 	}
@@ -373,7 +1279,19 @@ func (bidder *bidderAdapter) doTimeoutNotification(timeoutBidder adapters.Timeou
 }
 
 type httpCallInfo struct {
-	request  *adapters.RequestData
+	request *adapters.RequestData
+	// sentBody is the exact body which was handed to the HTTP client, or nil if the request couldn't be built.
+	// It's the same slice as request.Body unless the body was compressed.
+	sentBody []byte
 	response *adapters.ResponseData
-	err      error
+	// partialResponse holds the part of the response which was read before err occurred, if any.
+	// It's only meant for debugging, and must never be passed to the Bidder.
+	partialResponse *adapters.ResponseData
+	// connection is only set if the connection was traced.
+	connection *openrtb_ext.ExtHttpCallConnection
+	// cached is true if the response came from the Bidder's response cache, and nothing was sent.
+	cached bool
+	// originalURI is only set if a URIRewriter changed the URI of the request.
+	originalURI string
+	err         error
 }
@@ -1,19 +1,37 @@
 package exchange
 
 import (
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/adapters/adapterstest"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/currencies"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/pbsmetrics"
+	metricsConf "github.com/prebid/prebid-server/pbsmetrics/config"
 	"github.com/stretchr/testify/assert"
 
 	nativeRequests "github.com/mxmCherry/openrtb/native/request"
@@ -64,9 +82,9 @@ func TestSingleBidder(t *testing.T) {
 		},
 		bidResponse: mockBidderResponse,
 	}
-	bidder := adaptBidder(bidderImpl, server.Client())
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 	currencyConverter := currencies.NewRateConverterDefault()
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{})
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", bidAdjustment, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
 
 	// Make sure the goodSingleBidder was called with the expected arguments.
 	if bidderImpl.httpResponse == nil {
@@ -152,9 +170,9 @@ func TestMultiBidder(t *testing.T) {
 			}},
 		bidResponse: mockBidderResponse,
 	}
-	bidder := adaptBidder(bidderImpl, server.Client())
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 	currencyConverter := currencies.NewRateConverterDefault()
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{})
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
 
 	if seatBid == nil {
 		t.Fatalf("SeatBid should exist, because bids exist.")
@@ -197,7 +215,7 @@ func TestBidderTimeout(t *testing.T) {
 	callInfo := bidder.doRequest(ctx, &adapters.RequestData{
 		Method: "POST",
 		Uri:    server.URL,
-	})
+	}, false)
 	if callInfo.err == nil {
 		t.Errorf("The bidder should report an error if the context has expired already.")
 	}
@@ -216,7 +234,7 @@ func TestInvalidRequest(t *testing.T) {
 
 	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
 		Method: "\"", // force http.NewRequest() to fail
-	})
+	}, false)
 	if callInfo.err == nil {
 		t.Errorf("bidderAdapter.doRequest should return an error if the request data is malformed.")
 	}
@@ -238,7 +256,7 @@ func TestConnectionClose(t *testing.T) {
 	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
 		Method: "POST",
 		Uri:    server.URL,
-	})
+	}, false)
 	if callInfo.err == nil {
 		t.Errorf("bidderAdapter.doRequest should return an error if the connection closes unexpectedly.")
 	}
@@ -510,7 +528,7 @@ func TestMultiCurrencies(t *testing.T) {
 		)
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client())
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 		currencyConverter := currencies.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -523,6 +541,7 @@ func TestMultiCurrencies(t *testing.T) {
 			1,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
+			bidRequestOptions{},
 		)
 
 		// Verify:
@@ -539,6 +558,43 @@ func TestMultiCurrencies(t *testing.T) {
 }
 
 // TestMultiCurrencies_RateConverterNotSet no rate converter is set / active.
+// TestPerBidCurrency makes sure that bids which declare their own currency are converted from it,
+// and that bids without one fall back to the response currency.
+func TestPerBidCurrency(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Currency: "EUR",
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "response-currency", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "bid-currency", Price: 1}, BidType: openrtb_ext.BidTypeBanner, Currency: "GBP"},
+				{Bid: &openrtb.Bid{ID: "unknown-currency", Price: 1}, BidType: openrtb_ext.BidTypeBanner, Currency: "JPY"},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	rates := currencies.NewRates(time.Now(), map[string]map[string]float64{
+		"EUR": {"USD": 1.1},
+		"GBP": {"USD": 1.3},
+	})
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Cur: []string{"USD"}}, "test", 1.0, rates, &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Equal(t, "USD", seatBid.currency)
+	if assert.Len(t, seatBid.bids, 2) {
+		assert.Equal(t, "response-currency", seatBid.bids[0].bid.ID)
+		assert.InDelta(t, 1.1, seatBid.bids[0].bid.Price, 0.0001)
+		assert.Equal(t, "bid-currency", seatBid.bids[1].bid.ID)
+		assert.InDelta(t, 1.3, seatBid.bids[1].bid.Price, 0.0001)
+	}
+	assert.Len(t, errs, 1, "The bid in an unknown currency should be dropped with an error.")
+}
+
 func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 	// Setup:
 	respStatus := 200
@@ -658,7 +714,7 @@ func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client())
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 		currencyConverter := currencies.NewRateConverterDefault()
 		seatBid, errs := bidder.requestBid(
 			context.Background(),
@@ -667,6 +723,7 @@ func TestMultiCurrencies_RateConverterNotSet(t *testing.T) {
 			1,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
+			bidRequestOptions{},
 		)
 
 		// Verify:
@@ -824,7 +881,7 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 		}
 
 		// Execute:
-		bidder := adaptBidder(bidderImpl, server.Client())
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 		currencyConverter := currencies.NewRateConverter(
 			&http.Client{},
 			mockedHTTPServer.URL,
@@ -839,6 +896,7 @@ func TestMultiCurrencies_RequestCurrencyPick(t *testing.T) {
 			1,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
+			bidRequestOptions{},
 		)
 
 		// Verify:
@@ -939,7 +997,7 @@ func TestServerCallDebugging(t *testing.T) {
 			Headers: http.Header{},
 		},
 	}
-	bidder := adaptBidder(bidderImpl, server.Client())
+	bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 	currencyConverter := currencies.NewRateConverterDefault()
 
 	bids, _ := bidder.requestBid(
@@ -951,6 +1009,7 @@ func TestServerCallDebugging(t *testing.T) {
 		1.0,
 		currencyConverter.Rates(),
 		&adapters.ExtraRequestInfo{},
+		bidRequestOptions{},
 	)
 
 	if len(bids.httpCalls) != 1 {
@@ -1051,7 +1110,7 @@ func TestMobileNativeTypes(t *testing.T) {
 			},
 			bidResponse: tc.mockBidderResponse,
 		}
-		bidder := adaptBidder(bidderImpl, server.Client())
+		bidder := adaptBidder(bidderImpl, server.Client(), &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 		currencyConverter := currencies.NewRateConverterDefault()
 
 		seatBids, _ := bidder.requestBid(
@@ -1061,6 +1120,7 @@ func TestMobileNativeTypes(t *testing.T) {
 			1.0,
 			currencyConverter.Rates(),
 			&adapters.ExtraRequestInfo{},
+			bidRequestOptions{},
 		)
 
 		var actualValue string
@@ -1071,10 +1131,153 @@ func TestMobileNativeTypes(t *testing.T) {
 	}
 }
 
+func TestAddNativeTypesMultiFormatImp(t *testing.T) {
+	nativeAssets := "{\"assets\":[{\"id\":1,\"img\":{\"type\":3}},{\"id\":2,\"data\":{\"type\":2}}]}"
+	bid := &openrtb.Bid{
+		ImpID: "multi-format",
+		AdM:   "{\"assets\":[{\"id\":1,\"img\":{\"url\":\"http://some-url.com/img.jpg\",\"w\":300,\"h\":250}},{\"id\":2,\"data\":{\"value\":\"description\"}}]}",
+	}
+	expectedTypes := func(t *testing.T, description string, markup *nativeResponse.Response) {
+		if assert.NotNil(t, markup, description) && assert.Len(t, markup.Assets, 2, description) {
+			assert.EqualValues(t, 3, markup.Assets[0].Img.Type, description)
+			assert.EqualValues(t, 2, markup.Assets[1].Data.Type, description)
+		}
+	}
+
+	testCases := []struct {
+		description string
+		imps        []openrtb.Imp
+	}{
+		{
+			description: "Banner and native on the same imp",
+			imps: []openrtb.Imp{
+				{ID: "multi-format", Banner: &openrtb.Banner{}, Native: &openrtb.Native{Request: nativeAssets}},
+			},
+		},
+		{
+			description: "Imp split into a banner imp and a native imp with the same ID",
+			imps: []openrtb.Imp{
+				{ID: "multi-format", Banner: &openrtb.Banner{}},
+				{ID: "multi-format", Native: &openrtb.Native{Request: nativeAssets}},
+			},
+		},
+		{
+			description: "Native 1.0 request wrapped in a native object",
+			imps: []openrtb.Imp{
+				{ID: "multi-format", Banner: &openrtb.Banner{}, Native: &openrtb.Native{Request: "{\"native\":" + nativeAssets + "}"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		markup, errs := addNativeTypes(bid, &openrtb.BidRequest{Imp: test.imps})
+		assert.Empty(t, errs, test.description)
+		expectedTypes(t, test.description, markup)
+	}
+}
+
+func TestAddNativeTypesMissingNativeImp(t *testing.T) {
+	bid := &openrtb.Bid{
+		ImpID: "banner-only",
+		AdM:   "{\"assets\":[{\"id\":1,\"title\":{\"text\":\"title\"}}]}",
+	}
+
+	markup, errs := addNativeTypes(bid, &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "banner-only", Banner: &openrtb.Banner{}}}})
+	assert.Nil(t, markup)
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Contains(t, errs[0].Error(), "imp banner-only has no native object")
+	}
+
+	markup, errs = addNativeTypes(bid, &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "other-imp"}}})
+	assert.Nil(t, markup)
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "no imp has ID banner-only")
+	}
+}
+
+func TestAddNativeTypesImageSizeWarnings(t *testing.T) {
+	request := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{{
+			ID: "native-imp",
+			Native: &openrtb.Native{
+				Request: "{\"assets\":[{\"id\":1,\"img\":{\"type\":3,\"wmin\":100,\"hmin\":100}},{\"id\":2,\"img\":{\"type\":1,\"w\":50,\"h\":50}},{\"id\":3,\"img\":{\"type\":3}}]}",
+			},
+		}},
+	}
+	bid := &openrtb.Bid{
+		ID:    "bid-id",
+		ImpID: "native-imp",
+		AdM:   "{\"assets\":[{\"id\":1,\"img\":{\"url\":\"http://some-url.com/1.jpg\",\"w\":300}},{\"id\":2,\"img\":{\"url\":\"http://some-url.com/2.jpg\"}},{\"id\":3,\"img\":{\"url\":\"http://some-url.com/3.jpg\",\"w\":300,\"h\":250}}]}",
+	}
+
+	markup, errs := addNativeTypes(bid, request)
+
+	assert.NotNil(t, markup, "Size-less images shouldn't stop the enrichment.")
+	if assert.Len(t, errs, 1, "Only images without a size in either the request or the response should be reported.") {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "Bid bid-id has a native image asset with ID:1 whose size is missing, and the request didn't set one", errs[0].Error())
+	}
+}
+
+func TestMobileNativeTypesDisabled(t *testing.T) {
+	adm := "{\"assets\":[{\"id\":2,\"img\":{\"url\":\"http://some-image.jpg\",\"w\":989,\"h\":742}},{\"id\":3,\"data\":{\"value\":\"Prebid.org\"}}]}"
+	server := httptest.NewServer(mockHandler(200, "getBody", "{\"bid\":false}"))
+	defer server.Close()
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte("{\"key\":\"val\"}"),
+			Headers: http.Header{},
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{{
+				Bid: &openrtb.Bid{
+					ImpID: "some-imp-id",
+					AdM:   adm,
+					Price: 10,
+				},
+				BidType: openrtb_ext.BidTypeNative,
+			}},
+		},
+	}
+	cfg := &config.Configuration{
+		Adapters: map[string]config.Adapter{
+			"test": {DisableNativeEnrichment: true},
+		},
+	}
+	bidder := adaptBidder(bidderImpl, server.Client(), cfg, &metricsConf.DummyMetricsEngine{}, "test")
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, _ := bidder.requestBid(
+		context.Background(),
+		&openrtb.BidRequest{
+			Imp: []openrtb.Imp{{
+				ID: "some-imp-id",
+				Native: &openrtb.Native{
+					Request: "{\"assets\":[{\"id\":2,\"required\":1,\"img\":{\"type\":3}},{\"id\":3,\"required\":0,\"data\":{\"type\":1}}]}",
+				},
+			}},
+			App: &openrtb.App{},
+		},
+		"test",
+		1.0,
+		currencyConverter.Rates(),
+		&adapters.ExtraRequestInfo{},
+		bidRequestOptions{},
+	)
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, adm, seatBid.bids[0].bid.AdM, "The native markup should be untouched when enrichment is disabled.")
+	}
+}
+
 func TestErrorReporting(t *testing.T) {
-	bidder := adaptBidder(&bidRejector{}, nil)
+	bidder := adaptBidder(&bidRejector{}, nil, &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 	currencyConverter := currencies.NewRateConverterDefault()
-	bids, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{})
+	bids, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
 	if bids != nil {
 		t.Errorf("There should be no seatbid if no http requests are returned.")
 	}
@@ -1227,6 +1430,1946 @@ func TestSetAssetTypes(t *testing.T) {
 	}
 }
 
+// TestMockTransportResponses makes sure that a bidderAdapter backed by a MockTransport sees the canned responses.
+func TestMockTransportResponses(t *testing.T) {
+	bidder := newMockTransportBidder(&mixedMultiBidder{}, map[string]adapterstest.MockResponse{
+		"http://bidder.com/ok": {
+			StatusCode: 200,
+			Body:       "{\"bid\":true}",
+		},
+		"http://bidder.com/fail": {
+			StatusCode: 500,
+		},
+	})
+
+	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
+		Method: "POST",
+		Uri:    "http://bidder.com/ok",
+		Body:   []byte("{}"),
+	}, false)
+	if assert.NoError(t, callInfo.err, "Canned 200 responses should not produce an error.") {
+		assert.Equal(t, 200, callInfo.response.StatusCode)
+		assert.Equal(t, "{\"bid\":true}", string(callInfo.response.Body))
+	}
+
+	callInfo = bidder.doRequest(context.Background(), &adapters.RequestData{
+		Method: "POST",
+		Uri:    "http://bidder.com/fail",
+	}, false)
+	assert.IsType(t, &errortypes.BadServerResponse{}, callInfo.err, "Canned 500 responses should produce a BadServerResponse.")
+}
+
+// TestMockTransportTimeout makes sure that a delayed MockResponse triggers the timeout path in doRequest.
+func TestMockTransportTimeout(t *testing.T) {
+	bidder := newMockTransportBidder(&mixedMultiBidder{}, map[string]adapterstest.MockResponse{
+		"http://bidder.com/slow": {
+			StatusCode: 200,
+			Delay:      time.Second,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	callInfo := bidder.doRequest(ctx, &adapters.RequestData{
+		Method: "POST",
+		Uri:    "http://bidder.com/slow",
+	}, false)
+	assert.IsType(t, &errortypes.Timeout{}, callInfo.err, "Requests which outlive the context should produce a Timeout.")
+	assert.Nil(t, callInfo.response, "There should be no response if the request never completed.")
+}
+
+// TestMockTransportConnectionError makes sure that MockResponse.Err surfaces from doRequest.
+func TestMockTransportConnectionError(t *testing.T) {
+	connectionErr := errors.New("connection refused")
+	bidder := newMockTransportBidder(&mixedMultiBidder{}, map[string]adapterstest.MockResponse{
+		"http://bidder.com/down": {
+			Err: connectionErr,
+		},
+	})
+
+	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
+		Method: "POST",
+		Uri:    "http://bidder.com/down",
+	}, false)
+	if urlErr, ok := callInfo.err.(*url.Error); assert.True(t, ok, "Connection errors should be reported by doRequest. Got %v", callInfo.err) {
+		assert.Equal(t, connectionErr, urlErr.Err, "doRequest should report the connection error from the transport.")
+	}
+	assert.NotEqual(t, errortypes.TimeoutErrorCode, errortypes.ReadCode(callInfo.err), "Connection errors should not be reported as timeouts.")
+	assert.Nil(t, callInfo.response, "There should be no response if the connection failed.")
+}
+
+// TestCircuitBreakerSkipsFailingBidder makes sure that a bidder whose endpoint keeps failing stops getting called,
+// and that requestBid explains why it returned no bids.
+func TestCircuitBreakerSkipsFailingBidder(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/down",
+		},
+		bidResponse: &adapters.BidderResponse{},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/down": {
+			StatusCode: http.StatusServiceUnavailable,
+		},
+	})
+	bidder.breaker = newCircuitBreaker(config.CircuitBreaker{
+		FailureThreshold: 2,
+		WindowMillis:     60000,
+		CooldownMillis:   60000,
+	}, &metricsConf.DummyMetricsEngine{}, "test")
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	for i := 0; i < 2; i++ {
+		_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+		if assert.Len(t, errs, 1) {
+			assert.IsType(t, &errortypes.BadServerResponse{}, errs[0])
+		}
+	}
+
+	bidderImpl.httpResponse = nil
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.BidderUnavailable{}, errs[0], "An open breaker should produce an error.")
+	}
+	assert.Empty(t, seatBid.bids)
+	assert.Nil(t, bidderImpl.httpResponse, "MakeBids should not be called while the breaker is open.")
+}
+
+func TestCapBids(t *testing.T) {
+	makeBid := func(id string, impID string, price float64) *pbsOrtbBid {
+		return &pbsOrtbBid{bid: &openrtb.Bid{ID: id, ImpID: impID, Price: price}}
+	}
+	bids := []*pbsOrtbBid{
+		makeBid("a", "imp-1", 1),
+		makeBid("b", "imp-1", 3),
+		makeBid("c", "imp-2", 2),
+		makeBid("d", "imp-1", 2),
+	}
+
+	testCases := []struct {
+		description string
+		maxPerSeat  int
+		maxPerImp   int
+		expectedIDs []string
+	}{
+		{
+			description: "No limits",
+			expectedIDs: []string{"a", "b", "c", "d"},
+		},
+		{
+			description: "Seat limit keeps the highest prices in their original order",
+			maxPerSeat:  2,
+			expectedIDs: []string{"b", "c"},
+		},
+		{
+			description: "Imp limit applies to each imp separately",
+			maxPerImp:   1,
+			expectedIDs: []string{"b", "c"},
+		},
+		{
+			description: "Both limits",
+			maxPerSeat:  3,
+			maxPerImp:   2,
+			expectedIDs: []string{"b", "c", "d"},
+		},
+	}
+
+	for _, test := range testCases {
+		capped, numDropped := capBids(bids, test.maxPerSeat, test.maxPerImp)
+		ids := make([]string, 0, len(capped))
+		for _, bid := range capped {
+			ids = append(ids, bid.bid.ID)
+		}
+		assert.Equal(t, test.expectedIDs, ids, test.description)
+		assert.Equal(t, len(bids)-len(test.expectedIDs), numDropped, test.description)
+	}
+}
+
+func TestRequestBidCapsBids(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "low", ImpID: "imp-1", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "high", ImpID: "imp-1", Price: 2}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.config.MaxBidsPerImp = 1
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, pbsmetrics.CurrencySelectionFirst).Return()
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonBidLimit, 1).Return()
+	bidder.me = metricsMock
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, "high", seatBid.bids[0].bid.ID)
+	}
+	if assert.Len(t, errs, 1, "Dropped bids should be reported in a single warning.") {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRequestBidAllowedImps(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "banner-bid", ImpID: "banner-imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "video-bid", ImpID: "video-imp", Price: 2}, BidType: openrtb_ext.BidTypeVideo},
+				{Bid: &openrtb.Bid{ID: "other-bid", ImpID: "other-imp", Price: 3}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, pbsmetrics.CurrencySelectionFirst).Return()
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonImpNotAllowed, 2).Return()
+	bidder.me = metricsMock
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	options := bidRequestOptions{allowedImps: map[string]bool{"video-imp": true}}
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, options)
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, "video-bid", seatBid.bids[0].bid.ID)
+	}
+	if assert.Len(t, errs, 2, "Each dropped bid should get its own warning.") {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Contains(t, errs[0].Error(), "banner-imp")
+		assert.Contains(t, errs[1].Error(), "other-imp")
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRequestBidADomainFilter(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "good-bid", ImpID: "imp", Price: 1, ADomain: []string{"good.com"}}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "bad-bid", ImpID: "imp", Price: 2, ADomain: []string{"ads.bad.com"}}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, pbsmetrics.CurrencySelectionFirst).Return()
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonADomainDenied, 1).Return()
+	bidder.me = metricsMock
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	options := bidRequestOptions{adomainFilter: newADomainFilter(config.ADomainFilter{Denied: []string{"bad.com"}}, nil)}
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, options)
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, "good-bid", seatBid.bids[0].bid.ID)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Contains(t, errs[0].Error(), "ads.bad.com")
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRequestBidPrunesBidExt(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "pruned", ImpID: "imp", Price: 1, Ext: json.RawMessage(`{"dealtier":"gold","dsp_internal":{"margin":0.4},"seatname":"brand"}`)}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "emptied", ImpID: "imp", Price: 1, Ext: json.RawMessage(`{"dsp_internal":true}`)}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "invalid", ImpID: "imp", Price: 1, Ext: json.RawMessage(`["dsp_internal"]`)}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.config.BidExtAllowlist = newBidExtAllowlist([]string{"dealtier", "seatname"})
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, seatBid.bids, 3) {
+		assert.JSONEq(t, `{"dealtier":"gold","seatname":"brand"}`, string(seatBid.bids[0].bid.Ext))
+		assert.Nil(t, seatBid.bids[1].bid.Ext)
+		assert.Nil(t, seatBid.bids[2].bid.Ext)
+	}
+	if assert.Len(t, errs, 1, "Only the ext which isn't an object should be reported.") {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Contains(t, errs[0].Error(), "bid invalid")
+	}
+}
+
+func TestRequestBidKeepsBidExtWithoutAllowlist(t *testing.T) {
+	ext := `{"dealtier":"gold","dsp_internal":{"margin":0.4}}`
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "bid", ImpID: "imp", Price: 1, Ext: json.RawMessage(ext)}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.config.BidExtAllowlist = newBidExtAllowlist(nil)
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, ext, string(seatBid.bids[0].bid.Ext))
+	}
+}
+
+func TestRequestBidMaxMarkupBytes(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "small", ImpID: "imp", Price: 1, AdM: strings.Repeat("a", 10)}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "large", ImpID: "imp", Price: 2, AdM: strings.Repeat("a", 11)}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "nurl-only", ImpID: "imp", Price: 3, NURL: "http://bidder.com/adm"}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	bidder.config.MaxMarkupBytes = 10
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, pbsmetrics.CurrencySelectionFirst).Return()
+	metricsMock.On("RecordAdapterMarkupSize", openrtb_ext.BidderAppnexus, 10).Return()
+	metricsMock.On("RecordAdapterMarkupSize", openrtb_ext.BidderAppnexus, 11).Return()
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonMarkupTooLarge, 1).Return()
+	bidder.me = metricsMock
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, seatBid.bids, 2) {
+		assert.Equal(t, "small", seatBid.bids[0].bid.ID)
+		assert.Equal(t, "nurl-only", seatBid.bids[1].bid.ID)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Warning{}, errs[0])
+		assert.Equal(t, "Bid large was dropped because its markup is 11 bytes, which exceeds the limit of 10.", errs[0].Error())
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRequestBidNonFinitePrice(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "finite", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "nan", ImpID: "imp", Price: math.NaN()}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "inf", ImpID: "imp", Price: math.Inf(1)}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, pbsmetrics.CurrencySelectionFirst).Return()
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonInvalidPrice, 1).Return()
+	bidder.me = metricsMock
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, "finite", seatBid.bids[0].bid.ID)
+	}
+	if assert.Len(t, errs, 2) {
+		for _, err := range errs {
+			assert.IsType(t, &errortypes.Warning{}, err)
+		}
+	}
+	metricsMock.AssertNumberOfCalls(t, "RecordAdapterBidsDropped", 2)
+}
+
+func TestRequestBidCallback(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "kept", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "rejected", ImpID: "imp", Price: 2}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, pbsmetrics.CurrencySelectionFirst).Return()
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonRejected, 1).Return()
+	bidder.me = metricsMock
+
+	var seenBidders []openrtb_ext.BidderName
+	var seenPrices []float64
+	options := bidRequestOptions{
+		bidCallback: func(name openrtb_ext.BidderName, bid *pbsOrtbBid) error {
+			seenBidders = append(seenBidders, name)
+			seenPrices = append(seenPrices, bid.bid.Price)
+			if bid.bid.ID == "rejected" {
+				return errors.New("bid rejected")
+			}
+			bid.bid.Price += 0.5
+			bid.dealPriority = 7
+			return nil
+		},
+	}
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 2.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, options)
+
+	assert.Equal(t, []openrtb_ext.BidderName{"test", "test"}, seenBidders)
+	assert.Equal(t, []float64{2, 4}, seenPrices, "The callback should see the adjusted prices.")
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, "kept", seatBid.bids[0].bid.ID)
+		assert.Equal(t, 2.5, seatBid.bids[0].bid.Price)
+		assert.Equal(t, 7, seatBid.bids[0].dealPriority)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "bid rejected", errs[0].Error())
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	testCases := []struct {
+		description    string
+		headers        http.Header
+		expectedHeader string
+	}{
+		{description: "Requests without headers get the request ID", headers: nil, expectedHeader: "request-id"},
+		{description: "Requests with other headers get the request ID", headers: http.Header{"Content-Type": []string{"application/json"}}, expectedHeader: "request-id"},
+		{description: "The Bidder's own value wins", headers: http.Header{"X-Request-Id": []string{"bidder-value"}}, expectedHeader: "bidder-value"},
+	}
+
+	for _, test := range testCases {
+		var received http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header
+			w.Write([]byte("{}"))
+		}))
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method:  "POST",
+				Uri:     server.URL,
+				Headers: test.headers,
+			},
+		}
+		bidder := &bidderAdapter{
+			Bidder: bidderImpl,
+			Client: server.Client(),
+			me:     &metricsConf.DummyMetricsEngine{},
+			config: bidderAdapterConfig{RequestIDHeader: "X-Request-ID"},
+		}
+
+		_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{ID: "request-id"}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+		server.Close()
+
+		assert.Empty(t, errs, test.description)
+		assert.Equal(t, test.expectedHeader, received.Get("X-Request-ID"), test.description)
+		for name := range test.headers {
+			assert.Equal(t, test.headers.Get(name), received.Get(name), "The Bidder's other headers should be kept. "+test.description)
+		}
+		if test.expectedHeader == "request-id" {
+			assert.Empty(t, bidderImpl.httpRequest.Headers.Get("X-Request-ID"), "The Bidder's headers shouldn't be modified. "+test.description)
+		}
+	}
+}
+
+func TestRequestBidNoAllowedImps(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "banner-bid", ImpID: "banner-imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "video-bid", ImpID: "video-imp", Price: 2}, BidType: openrtb_ext.BidTypeVideo},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	assert.Len(t, seatBid.bids, 2, "All bids should be kept if the request has no allowlist for this bidder.")
+}
+
+func TestNoContentIsNoBid(t *testing.T) {
+	testCases := []struct {
+		description      string
+		noContentIsNoBid bool
+		expectMakeBids   bool
+	}{
+		{description: "204 responses are passed to MakeBids by default", noContentIsNoBid: false, expectMakeBids: true},
+		{description: "204 responses skip MakeBids when configured", noContentIsNoBid: true, expectMakeBids: false},
+	}
+	for _, test := range testCases {
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    "http://bidder.com/bid",
+			},
+		}
+		bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+			"http://bidder.com/bid": {StatusCode: http.StatusNoContent},
+		})
+		bidder.config.NoContentIsNoBid = test.noContentIsNoBid
+
+		seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+		assert.Empty(t, errs, test.description)
+		if assert.NotNil(t, seatBid, test.description) {
+			assert.Empty(t, seatBid.bids, test.description)
+		}
+		assert.Equal(t, test.expectMakeBids, bidderImpl.httpResponse != nil, test.description)
+	}
+}
+
+func TestWarnOnEmptyResponse(t *testing.T) {
+	testCases := []struct {
+		description         string
+		warnOnEmptyResponse bool
+		body                string
+		expectWarning       bool
+	}{
+		{description: "Empty 200s are passed to MakeBids by default", body: ""},
+		{description: "Empty 200s are reported when configured", warnOnEmptyResponse: true, body: "", expectWarning: true},
+		{description: "Whitespace-only bodies count as empty", warnOnEmptyResponse: true, body: " \r\n\t", expectWarning: true},
+		{description: "Non-empty bodies are passed to MakeBids", warnOnEmptyResponse: true, body: "{}"},
+	}
+	for _, test := range testCases {
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    "http://bidder.com/bid",
+			},
+		}
+		bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+			"http://bidder.com/bid": {StatusCode: http.StatusOK, Body: test.body},
+		})
+		bidder.config.WarnOnEmptyResponse = test.warnOnEmptyResponse
+
+		seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+		if test.expectWarning {
+			if assert.Len(t, errs, 1, test.description) {
+				assert.IsType(t, &errortypes.Warning{}, errs[0], test.description)
+			}
+			assert.Nil(t, bidderImpl.httpResponse, "MakeBids shouldn't be called. "+test.description)
+		} else {
+			assert.Empty(t, errs, test.description)
+			assert.NotNil(t, bidderImpl.httpResponse, "MakeBids should be called. "+test.description)
+		}
+		if assert.NotNil(t, seatBid, test.description) {
+			assert.Empty(t, seatBid.bids, test.description)
+		}
+	}
+}
+
+func TestNormalizeBidExpirations(t *testing.T) {
+	cfg := config.BidExpirations{
+		Banner: config.BidExpiration{DefaultSeconds: 300, MinSeconds: 60, MaxSeconds: 600},
+		Video:  config.BidExpiration{MaxSeconds: 3600},
+	}
+	testCases := []struct {
+		description string
+		bidType     openrtb_ext.BidType
+		exp         int64
+		expectedExp int64
+		expectWarn  bool
+	}{
+		{description: "Missing exp gets the default", bidType: openrtb_ext.BidTypeBanner, exp: 0, expectedExp: 300},
+		{description: "Exp within the limits is kept", bidType: openrtb_ext.BidTypeBanner, exp: 120, expectedExp: 120},
+		{description: "Exp above the max is clamped", bidType: openrtb_ext.BidTypeBanner, exp: 86400, expectedExp: 600, expectWarn: true},
+		{description: "Exp below the min is raised", bidType: openrtb_ext.BidTypeBanner, exp: 10, expectedExp: 60, expectWarn: true},
+		{description: "Limits are per media type", bidType: openrtb_ext.BidTypeVideo, exp: 1800, expectedExp: 1800},
+		{description: "Missing exp is kept without a default", bidType: openrtb_ext.BidTypeVideo, exp: 0, expectedExp: 0},
+		{description: "Media types without limits are untouched", bidType: openrtb_ext.BidTypeNative, exp: 86400, expectedExp: 86400},
+	}
+	for _, test := range testCases {
+		bid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "bid", Exp: test.exp}, bidType: test.bidType}
+
+		errs := normalizeBidExpirations([]*pbsOrtbBid{bid}, cfg)
+
+		assert.Equal(t, test.expectedExp, bid.bid.Exp, test.description)
+		if test.expectWarn {
+			if assert.Len(t, errs, 1, test.description) {
+				assert.IsType(t, &errortypes.Warning{}, errs[0], test.description)
+			}
+		} else {
+			assert.Empty(t, errs, test.description)
+		}
+	}
+}
+
+func TestBidsTaggedWithHttpCall(t *testing.T) {
+	bidderImpl := &goodMultiHTTPCallsBidder{
+		httpRequest: []*adapters.RequestData{
+			{Method: "POST", Uri: "http://bidder.com/one"},
+			{Method: "POST", Uri: "http://bidder.com/two"},
+		},
+		bidResponses: []*adapters.BidderResponse{
+			{Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "from-first-response", ImpID: "imp-1", Price: 1}, BidType: openrtb_ext.BidTypeBanner}}},
+			{Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "from-second-response", ImpID: "imp-1", Price: 1}, BidType: openrtb_ext.BidTypeBanner}}},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/one": {Body: "{}"},
+		"http://bidder.com/two": {Body: "{}"},
+	})
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Test: 1}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	if assert.Len(t, seatBid.bids, 2) && assert.Len(t, seatBid.httpCalls, 2) {
+		// MakeBids is called in the order the responses arrive, which is also the order of the httpCalls.
+		for i, bid := range seatBid.bids {
+			if assert.NotNil(t, bid.httpCall, bid.bid.ID) {
+				assert.Equal(t, i, *bid.httpCall, bid.bid.ID)
+			}
+		}
+	}
+}
+
+func TestBidsNotTaggedOutsideTestMode(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "bid", ImpID: "imp-1", Price: 1}, BidType: openrtb_ext.BidTypeBanner}},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+
+	seatBid, _ := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Nil(t, seatBid.bids[0].httpCall)
+	}
+}
+
+func TestGenerateBidIDs(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "", ImpID: "imp-1", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "kept", ImpID: "imp-2", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "", ImpID: "imp-3", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	bidder.config.GenerateBidIDs = true
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, pbsmetrics.CurrencySelectionFirst).Return()
+	metricsMock.On("RecordAdapterGeneratedBidIDs", openrtb_ext.BidderAppnexus, 2).Return()
+	bidder.me = metricsMock
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	if assert.Len(t, seatBid.bids, 3) {
+		assert.NotEmpty(t, seatBid.bids[0].bid.ID)
+		assert.Equal(t, "kept", seatBid.bids[1].bid.ID, "Existing bid IDs should be kept.")
+		assert.NotEmpty(t, seatBid.bids[2].bid.ID)
+		assert.NotEqual(t, seatBid.bids[0].bid.ID, seatBid.bids[2].bid.ID, "Generated bid IDs should be unique.")
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestGenerateBidIDsDisabled(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ImpID: "imp-1", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, _ := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Empty(t, seatBid.bids[0].bid.ID, "Bid IDs should only be generated for bidders which opt in.")
+	}
+}
+
+func TestRoundPrice(t *testing.T) {
+	testCases := []struct {
+		price    float64
+		decimals int
+		expected float64
+	}{
+		{price: 1.2300000000001, decimals: 0, expected: 1.2300000000001},
+		{price: 1.2300000000001, decimals: 4, expected: 1.23},
+		{price: 1.23456, decimals: 4, expected: 1.2346},
+		{price: 1.23454, decimals: 4, expected: 1.2345},
+		{price: 0.00004, decimals: 4, expected: 0},
+	}
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, roundPrice(test.price, test.decimals), "price %v to %d decimals", test.price, test.decimals)
+	}
+}
+
+func TestRequestBidRoundsPrices(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Currency: "EUR",
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "some-bid", Price: 1.11111}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.config.PriceDecimals = 4
+	rates := currencies.NewRates(time.Now(), map[string]map[string]float64{
+		"EUR": {"USD": 1.1},
+	})
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Cur: []string{"USD"}}, "test", 0.9, rates, &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, 1.1, seatBid.bids[0].bid.Price, "The price should be rounded after both the adjustment and the conversion.")
+	}
+}
+
+func TestConversionDebugInfo(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Currency: "EUR",
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "eur-bid", Price: 2}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "other-eur-bid", Price: 3}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "gbp-bid", Price: 2}, BidType: openrtb_ext.BidTypeBanner, Currency: "GBP"},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	rates := currencies.NewRates(time.Now(), map[string]map[string]float64{
+		"EUR": {"USD": 1.1},
+		"GBP": {"USD": 1.3},
+	})
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Test: 1, Cur: []string{"USD"}}, "test", 1.0, rates, &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	assert.Equal(t, []openrtb_ext.ExtCurrencyConversion{
+		{From: "EUR", To: "USD", Rate: 1.1},
+		{From: "GBP", To: "USD", Rate: 1.3},
+	}, seatBid.conversions, "Each distinct conversion should be listed once.")
+	if assert.Len(t, seatBid.bids, 3) {
+		assert.Equal(t, 2*1.1, seatBid.bids[0].bid.Price, "The debug info should show the rate which was applied.")
+	}
+}
+
+func TestConversionDebugInfoOutsideTestMode(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Currency: "EUR",
+			Bids:     []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "eur-bid", Price: 2}, BidType: openrtb_ext.BidTypeBanner}},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	rates := currencies.NewRates(time.Now(), map[string]map[string]float64{
+		"EUR": {"USD": 1.1},
+	})
+
+	seatBid, _ := bidder.requestBid(context.Background(), &openrtb.BidRequest{Cur: []string{"USD"}}, "test", 1.0, rates, &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, seatBid.conversions)
+}
+
+// TestUnconvertedBidsMetric makes sure that bids which are thrown out for lack of a conversion rate are counted.
+func TestUnconvertedBidsMetric(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Currency: "JPY",
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "one", ImpID: "imp-1", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "two", ImpID: "imp-2", Price: 2}, BidType: openrtb_ext.BidTypeVideo},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonCurrencyConversion, 2).Return()
+	bidder.me = metricsMock
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Cur: []string{"USD"}}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Len(t, seatBid.bids, 0)
+	assert.Len(t, errs, 1)
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRequestBidCurrencyPrecedence(t *testing.T) {
+	rates := currencies.NewRates(time.Now(), map[string]map[string]float64{
+		"USD": {"EUR": 0.9},
+	})
+
+	testCases := []struct {
+		description       string
+		requestCur        []string
+		fallbackCurrency  string
+		expectedCurrency  string
+		expectedSelection pbsmetrics.CurrencySelection
+		expectedPrice     float64
+	}{
+		{
+			description:       "The first currency in request.cur is used if the bids convert to it",
+			requestCur:        []string{"EUR", "USD"},
+			expectedCurrency:  "EUR",
+			expectedSelection: pbsmetrics.CurrencySelectionFirst,
+			expectedPrice:     0.9,
+		},
+		{
+			description:       "Unsupported currencies in request.cur are skipped in order",
+			requestCur:        []string{"JPY", "EUR", "USD"},
+			expectedCurrency:  "EUR",
+			expectedSelection: pbsmetrics.CurrencySelectionOther,
+			expectedPrice:     0.9,
+		},
+		{
+			description:       "The fallback currency is used if no currency in request.cur is supported",
+			requestCur:        []string{"JPY", "GBP"},
+			fallbackCurrency:  "EUR",
+			expectedCurrency:  "EUR",
+			expectedSelection: pbsmetrics.CurrencySelectionFallback,
+			expectedPrice:     0.9,
+		},
+		{
+			description:       "The fallback currency isn't used while request.cur has a supported currency",
+			requestCur:        []string{"JPY", "USD"},
+			fallbackCurrency:  "EUR",
+			expectedCurrency:  "USD",
+			expectedSelection: pbsmetrics.CurrencySelectionOther,
+			expectedPrice:     1,
+		},
+	}
+
+	for _, test := range testCases {
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    "http://bidder.com/bid",
+			},
+			bidResponse: &adapters.BidderResponse{
+				Currency: "USD",
+				Bids: []*adapters.TypedBid{
+					{Bid: &openrtb.Bid{ID: "bid", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+				},
+			},
+		}
+		bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+			"http://bidder.com/bid": {Body: "{}"},
+		})
+		bidder.BidderName = openrtb_ext.BidderAppnexus
+		metricsMock := &pbsmetrics.MetricsEngineMock{}
+		metricsMock.On("RecordAdapterCurrencySelection", openrtb_ext.BidderAppnexus, test.expectedSelection).Return()
+		bidder.me = metricsMock
+
+		options := bidRequestOptions{fallbackCurrency: test.fallbackCurrency}
+		seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Cur: test.requestCur}, "test", 1.0, rates, &adapters.ExtraRequestInfo{}, options)
+
+		assert.Empty(t, errs, test.description)
+		assert.Equal(t, test.expectedCurrency, seatBid.currency, test.description)
+		if assert.Len(t, seatBid.bids, 1, test.description) {
+			assert.InDelta(t, test.expectedPrice, seatBid.bids[0].bid.Price, 0.0001, test.description)
+		}
+		metricsMock.AssertExpectations(t)
+	}
+}
+
+func TestRequestBidCurrencyWithoutFallback(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Currency: "USD",
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "bid", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterBidsDropped", openrtb_ext.BidderAppnexus, pbsmetrics.BidDropReasonCurrencyConversion, 1).Return()
+	bidder.me = metricsMock
+	rates := currencies.NewRates(time.Now(), map[string]map[string]float64{})
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Cur: []string{"JPY", "GBP"}}, "test", 1.0, rates, &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, seatBid.bids)
+	assert.Len(t, errs, 1)
+	metricsMock.AssertExpectations(t)
+}
+
+func TestRequestBidPartialSeat(t *testing.T) {
+	testCases := []struct {
+		description     string
+		responses       map[string]adapterstest.MockResponse
+		expectedPartial bool
+	}{
+		{
+			description: "All requests succeeded",
+			responses: map[string]adapterstest.MockResponse{
+				"http://bidder.com/one": {Body: "{}"},
+				"http://bidder.com/two": {Body: "{}"},
+			},
+			expectedPartial: false,
+		},
+		{
+			description: "One request failed",
+			responses: map[string]adapterstest.MockResponse{
+				"http://bidder.com/one": {Body: "{}"},
+				"http://bidder.com/two": {StatusCode: http.StatusInternalServerError},
+			},
+			expectedPartial: true,
+		},
+		{
+			description:     "All requests failed",
+			responses:       map[string]adapterstest.MockResponse{},
+			expectedPartial: false,
+		},
+	}
+
+	for _, test := range testCases {
+		bidderImpl := &goodMultiHTTPCallsBidder{
+			httpRequest: []*adapters.RequestData{
+				{Method: "POST", Uri: "http://bidder.com/one"},
+				{Method: "POST", Uri: "http://bidder.com/two"},
+			},
+			bidResponses: []*adapters.BidderResponse{
+				{Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "bid-1", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner}}},
+				{Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "bid-2", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner}}},
+			},
+		}
+		bidder := newMockTransportBidder(bidderImpl, test.responses)
+
+		seatBid, _ := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+		assert.Equal(t, test.expectedPartial, seatBid.partial, test.description)
+		assert.Len(t, seatBid.bids, bidderImpl.bidResponseNumber, "%s: the flag shouldn't change which bids are returned.", test.description)
+	}
+}
+
+func TestRequestBidMatchingCurrencies(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+		bidResponse: &adapters.BidderResponse{
+			Currency: "SEK",
+			Bids: []*adapters.TypedBid{
+				{Bid: &openrtb.Bid{ID: "response-currency", ImpID: "imp", Price: 1.5}, BidType: openrtb_ext.BidTypeBanner},
+				{Bid: &openrtb.Bid{ID: "bid-currency", ImpID: "imp", Price: 2}, BidType: openrtb_ext.BidTypeBanner, Currency: "sek"},
+			},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Cur: []string{"SEK"}}, "test", 1.0, &failingConversions{}, &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	assert.Equal(t, "SEK", seatBid.currency)
+	if assert.Len(t, seatBid.bids, 2) {
+		assert.Equal(t, 1.5, seatBid.bids[0].bid.Price)
+		assert.Equal(t, 2.0, seatBid.bids[1].bid.Price)
+	}
+}
+
+// failingConversions has no rates at all.
+type failingConversions struct{}
+
+func (c *failingConversions) GetRate(from string, to string) (float64, error) {
+	return 0, fmt.Errorf("Currency conversion rate not found: '%s' => '%s'", from, to)
+}
+
+func (c *failingConversions) GetRates() *map[string]map[string]float64 {
+	return nil
+}
+
+func TestRequestBidPartialTimeout(t *testing.T) {
+	bidderImpl := &goodMultiHTTPCallsBidder{
+		httpRequest: []*adapters.RequestData{
+			{Method: "POST", Uri: "http://bidder.com/fast"},
+			{Method: "POST", Uri: "http://bidder.com/slow"},
+		},
+		bidResponses: []*adapters.BidderResponse{
+			{Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "fast-bid", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner}}},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/fast": {Body: "{}"},
+		"http://bidder.com/slow": {Body: "{}", Delay: time.Second},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	seatBid, errs := bidder.requestBid(ctx, &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.True(t, seatBid.partial)
+	assert.True(t, seatBid.partialTimeout)
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, "fast-bid", seatBid.bids[0].bid.ID)
+	}
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Timeout{}, errs[0])
+	}
+}
+
+func TestRequestBidPartialWithoutTimeout(t *testing.T) {
+	bidderImpl := &goodMultiHTTPCallsBidder{
+		httpRequest: []*adapters.RequestData{
+			{Method: "POST", Uri: "http://bidder.com/ok"},
+			{Method: "POST", Uri: "http://bidder.com/error"},
+		},
+		bidResponses: []*adapters.BidderResponse{
+			{Bids: []*adapters.TypedBid{{Bid: &openrtb.Bid{ID: "bid", ImpID: "imp", Price: 1}, BidType: openrtb_ext.BidTypeBanner}}},
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/ok":    {Body: "{}"},
+		"http://bidder.com/error": {StatusCode: http.StatusBadRequest},
+	})
+
+	seatBid, _ := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.True(t, seatBid.partial)
+	assert.False(t, seatBid.partialTimeout, "Seats which lost bids to other errors shouldn't be blamed on the timeout.")
+}
+
+// TestPartialResponseDebugging makes sure that the bytes read before a failure show up in the debug output,
+// but aren't given to the Bidder.
+func TestPartialResponseDebugging(t *testing.T) {
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+			Body:   []byte("{}"),
+		},
+		bidResponse: &adapters.BidderResponse{},
+	}
+	bidder := &bidderAdapter{
+		Bidder: bidderImpl,
+		Client: &http.Client{Transport: &partialBodyTransport{body: `{"id":"some-`}},
+		me:     &metricsConf.DummyMetricsEngine{},
+	}
+	currencyConverter := currencies.NewRateConverterDefault()
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Test: 1}, "test", 1.0, currencyConverter.Rates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, errs, 1) {
+		assert.EqualError(t, errs[0], "connection reset mid-read")
+	}
+	assert.Nil(t, bidderImpl.httpResponse, "A partial response should never be passed to MakeBids.")
+	if assert.Len(t, seatBid.httpCalls, 1) {
+		assert.Equal(t, &openrtb_ext.ExtHttpCall{
+			Uri:             "http://bidder.com/bid",
+			RequestBody:     "{}",
+			ResponseBody:    `{"id":"some-`,
+			Status:          200,
+			PartialResponse: true,
+		}, seatBid.httpCalls[0])
+	}
+}
+
+// partialBodyTransport responds with a body which fails after the given bytes have been read.
+type partialBodyTransport struct {
+	body string
+}
+
+func (t *partialBodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(io.MultiReader(strings.NewReader(t.body), &failingReader{})),
+		Request:    req,
+	}, nil
+}
+
+// failingReader always fails.
+type failingReader struct{}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset mid-read")
+}
+
+func TestBidderClientShared(t *testing.T) {
+	shared := &http.Client{Transport: &http.Transport{}}
+	hostCfg := config.HTTPClient{MaxIdleConns: 400, MaxIdleConnsPerHost: 10, IdleConnTimeout: 60}
+
+	assert.True(t, shared == bidderClient(shared, hostCfg, config.HTTPClient{}, nil), "Bidders without their own config should share the client.")
+}
+
+func TestBidderClientOwnPool(t *testing.T) {
+	tlsConfig := &tls.Config{}
+	shared := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   time.Second,
+	}
+	hostCfg := config.HTTPClient{MaxIdleConns: 400, MaxIdleConnsPerHost: 10, IdleConnTimeout: 60}
+
+	client := bidderClient(shared, hostCfg, config.HTTPClient{MaxIdleConnsPerHost: 100}, nil)
+
+	if assert.False(t, shared == client, "Bidders with their own config should get their own client.") {
+		assert.Equal(t, time.Second, client.Timeout)
+		transport := client.Transport.(*http.Transport)
+		assert.Equal(t, 400, transport.MaxIdleConns, "Unset values should come from the host config.")
+		assert.Equal(t, 100, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 60*time.Second, transport.IdleConnTimeout)
+		assert.True(t, tlsConfig == transport.TLSClientConfig, "The shared TLS config should be kept.")
+	}
+}
+
+func TestBidderClientTimeouts(t *testing.T) {
+	shared := &http.Client{Transport: &http.Transport{}}
+	hostCfg := config.HTTPClient{ConnectTimeoutMs: 1000, TLSHandshakeTimeoutMs: 1000, ResponseHeaderTimeoutMs: 5000}
+
+	client := bidderClient(shared, hostCfg, config.HTTPClient{ResponseHeaderTimeoutMs: 300}, nil)
+
+	transport := client.Transport.(*http.Transport)
+	assert.Equal(t, time.Second, transport.TLSHandshakeTimeout, "Unset timeouts should come from the host config.")
+	assert.Equal(t, 300*time.Millisecond, transport.ResponseHeaderTimeout)
+	assert.NotNil(t, transport.DialContext, "The connect timeout should be applied through DialContext.")
+}
+
+func TestBidderClientResponseHeaderTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	// Never reading the request leaves the response headers outstanding, which only the ResponseHeaderTimeout can end.
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				for _, conn := range conns {
+					conn.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	client := bidderClient(nil, config.HTTPClient{}, config.HTTPClient{ResponseHeaderTimeoutMs: 50}, nil)
+	start := time.Now()
+	_, err = client.Get("http://" + listener.Addr().String())
+
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second, "The response header timeout should end the call.")
+}
+
+func TestBidderTLSConfig(t *testing.T) {
+	tlsConfig, err := bidderTLSConfig(config.TLSClient{})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig, "Bidders without TLS settings should keep the host's setup.")
+
+	tlsConfig, err = bidderTLSConfig(config.TLSClient{
+		CertFile: "test/tls/client.pem",
+		KeyFile:  "test/tls/client-key.pem",
+		CAFile:   "test/tls/ca.pem",
+	})
+	if assert.NoError(t, err) {
+		assert.Len(t, tlsConfig.Certificates, 1)
+		assert.NotNil(t, tlsConfig.RootCAs)
+	}
+
+	_, err = bidderTLSConfig(config.TLSClient{CertFile: "test/tls/missing.pem", KeyFile: "test/tls/client-key.pem"})
+	assert.Error(t, err, "Missing certificates should be reported.")
+
+	_, err = bidderTLSConfig(config.TLSClient{CAFile: "test/tls/client-key.pem"})
+	assert.EqualError(t, err, "CA file test/tls/client-key.pem has no PEM certificates")
+}
+
+func TestBidderClientTLSKeepsSharedCAs(t *testing.T) {
+	sharedCAs := x509.NewCertPool()
+	shared := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: sharedCAs}}}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{{}}}
+
+	client := bidderClient(shared, config.HTTPClient{}, config.HTTPClient{}, tlsConfig)
+
+	if assert.False(t, shared == client, "Bidders with their own TLS setup should get their own client.") {
+		transportTLS := client.Transport.(*http.Transport).TLSClientConfig
+		assert.Len(t, transportTLS.Certificates, 1)
+		assert.True(t, sharedCAs == transportTLS.RootCAs, "The shared CAs should be trusted if the Bidder has none of its own.")
+		assert.Nil(t, tlsConfig.RootCAs, "The Bidder's TLS config shouldn't be modified.")
+	}
+}
+
+func TestRequestBidMutualTLS(t *testing.T) {
+	clientCAs := x509.NewCertPool()
+	caPEM, err := ioutil.ReadFile("test/tls/ca.pem")
+	if err != nil || !clientCAs.AppendCertsFromPEM(caPEM) {
+		t.Fatalf("Failed to load the test CA: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+		w.Write([]byte("{}"))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCA, err := ioutil.TempFile("", "server-ca-*.pem")
+	if err != nil {
+		t.Fatalf("Failed to create the server CA file: %v", err)
+	}
+	defer os.Remove(serverCA.Name())
+	pem.Encode(serverCA, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	serverCA.Close()
+
+	testCases := []struct {
+		description   string
+		tls           config.TLSClient
+		path          string
+		timeout       time.Duration
+		expectError   bool
+		expectTimeout bool
+	}{
+		{
+			description: "Bidders with a client certificate are let in",
+			tls:         config.TLSClient{CertFile: "test/tls/client.pem", KeyFile: "test/tls/client-key.pem", CAFile: serverCA.Name()},
+			path:        "/bid",
+		},
+		{
+			description:   "Slow responses over mutual TLS still time out",
+			tls:           config.TLSClient{CertFile: "test/tls/client.pem", KeyFile: "test/tls/client-key.pem", CAFile: serverCA.Name()},
+			path:          "/slow",
+			timeout:       50 * time.Millisecond,
+			expectError:   true,
+			expectTimeout: true,
+		},
+		{
+			description: "Bidders without a client certificate are turned away",
+			tls:         config.TLSClient{CAFile: serverCA.Name()},
+			path:        "/bid",
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		tlsConfig, err := bidderTLSConfig(test.tls)
+		if err != nil {
+			t.Fatalf("%s: failed to set up TLS: %v", test.description, err)
+		}
+		bidder := &bidderAdapter{
+			Bidder: &goodSingleBidder{
+				httpRequest: &adapters.RequestData{Method: "POST", Uri: server.URL + test.path},
+			},
+			Client: bidderClient(nil, config.HTTPClient{}, config.HTTPClient{}, tlsConfig),
+			me:     &metricsConf.DummyMetricsEngine{},
+		}
+		ctx := context.Background()
+		if test.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, test.timeout)
+			defer cancel()
+		}
+
+		_, errs := bidder.requestBid(ctx, &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+		if !test.expectError {
+			assert.Empty(t, errs, test.description)
+		} else if assert.Len(t, errs, 1, test.description) {
+			_, isTimeout := errs[0].(*errortypes.Timeout)
+			assert.Equal(t, test.expectTimeout, isTimeout, "%s: got %v", test.description, errs[0])
+		}
+	}
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/twice":
+			http.Redirect(w, r, "/once", http.StatusFound)
+		case "/once":
+			http.Redirect(w, r, "/bid", http.StatusFound)
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		description   string
+		redirects     config.Redirects
+		path          string
+		expectedError string
+	}{
+		{description: "Redirects are followed by default", path: "/twice"},
+		{description: "Redirects within the limit are followed", redirects: config.Redirects{Max: 2}, path: "/twice"},
+		{description: "Redirects past the limit fail", redirects: config.Redirects{Max: 1}, path: "/twice", expectedError: "after 1 redirects, but at most 1 are allowed"},
+		{description: "Disabled redirects fail", redirects: config.Redirects{Disabled: true}, path: "/once", expectedError: "redirects are disabled"},
+		{description: "Disabled redirects don't affect other requests", redirects: config.Redirects{Disabled: true}, path: "/bid"},
+	}
+
+	for _, test := range testCases {
+		bidderImpl := &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    server.URL + test.path,
+			},
+		}
+		bidder := &bidderAdapter{
+			Bidder: bidderImpl,
+			Client: withRedirectPolicy(server.Client(), test.redirects),
+			me:     &metricsConf.DummyMetricsEngine{},
+		}
+
+		_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+		if test.expectedError == "" {
+			assert.Empty(t, errs, test.description)
+			assert.NotNil(t, bidderImpl.httpResponse, test.description)
+		} else if assert.Len(t, errs, 1, test.description) {
+			assert.Contains(t, errs[0].Error(), test.expectedError, test.description)
+			assert.Nil(t, bidderImpl.httpResponse, test.description)
+		}
+	}
+}
+
+func TestDecodeToUTF8(t *testing.T) {
+	testCases := []struct {
+		description  string
+		body         string
+		contentType  string
+		expectedBody string
+		expectError  bool
+	}{
+		{description: "No content type", body: "caf\xe9", contentType: "", expectedBody: "caf\xe9"},
+		{description: "No charset", body: "caf\xe9", contentType: "application/json", expectedBody: "caf\xe9"},
+		{description: "Already UTF-8", body: "café", contentType: "application/json; charset=UTF-8", expectedBody: "café"},
+		{description: "ISO-8859-1", body: "caf\xe9", contentType: "application/json; charset=ISO-8859-1", expectedBody: "café"},
+		{description: "Unknown charset", body: "caf\xe9", contentType: "application/json; charset=made-up", expectedBody: "caf\xe9", expectError: true},
+	}
+	for _, test := range testCases {
+		body, err := decodeToUTF8([]byte(test.body), test.contentType)
+		assert.Equal(t, test.expectedBody, string(body), test.description)
+		if test.expectError {
+			assert.IsType(t, &errortypes.BadServerResponse{}, err, test.description)
+		} else {
+			assert.NoError(t, err, test.description)
+		}
+	}
+}
+
+func TestDoRequestDecodesCharset(t *testing.T) {
+	bidder := newMockTransportBidder(&goodSingleBidder{}, map[string]adapterstest.MockResponse{
+		"http://bidder.com/latin1": {
+			Body:    "{\"adm\":\"caf\xe9\"}",
+			Headers: http.Header{"Content-Type": []string{"application/json; charset=ISO-8859-1"}},
+		},
+		"http://bidder.com/unknown": {
+			Body:    "{}",
+			Headers: http.Header{"Content-Type": []string{"application/json; charset=made-up"}},
+		},
+	})
+
+	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{Method: "POST", Uri: "http://bidder.com/latin1"}, false)
+	if assert.NoError(t, callInfo.err) {
+		assert.Equal(t, `{"adm":"café"}`, string(callInfo.response.Body))
+	}
+
+	callInfo = bidder.doRequest(context.Background(), &adapters.RequestData{Method: "POST", Uri: "http://bidder.com/unknown"}, false)
+	assert.IsType(t, &errortypes.BadServerResponse{}, callInfo.err)
+	if assert.NotNil(t, callInfo.response, "The raw response should still be available for debugging.") {
+		assert.Equal(t, "{}", string(callInfo.response.Body))
+	}
+}
+
+// deadlineRecordingTransport responds to every request with an empty JSON body, and records the request's deadline.
+type deadlineRecordingTransport struct {
+	lock      sync.Mutex
+	deadlines []time.Time
+}
+
+func (t *deadlineRecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if deadline, ok := req.Context().Deadline(); ok {
+		t.lock.Lock()
+		t.deadlines = append(t.deadlines, deadline)
+		t.lock.Unlock()
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestSplitRequestDeadline(t *testing.T) {
+	testCases := []struct {
+		description   string
+		split         bool
+		expectedShare time.Duration
+	}{
+		{description: "Requests share the whole deadline by default", split: false, expectedShare: time.Second},
+		{description: "Each request gets an equal part of the deadline", split: true, expectedShare: 500 * time.Millisecond},
+	}
+	for _, test := range testCases {
+		transport := &deadlineRecordingTransport{}
+		bidder := &bidderAdapter{
+			Bidder: &goodMultiHTTPCallsBidder{
+				httpRequest: []*adapters.RequestData{
+					{Method: "POST", Uri: "http://bidder.com/one"},
+					{Method: "POST", Uri: "http://bidder.com/two"},
+				},
+				bidResponses: make([]*adapters.BidderResponse, 2),
+			},
+			Client: &http.Client{Transport: transport},
+			me:     &metricsConf.DummyMetricsEngine{},
+			config: bidderAdapterConfig{SplitRequestDeadline: test.split},
+		}
+		start := time.Now()
+		ctx, cancel := context.WithDeadline(context.Background(), start.Add(time.Second))
+		bidder.requestBid(ctx, &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+		cancel()
+
+		if assert.Len(t, transport.deadlines, 2, test.description) {
+			for _, deadline := range transport.deadlines {
+				assert.WithinDuration(t, start.Add(test.expectedShare), deadline, 100*time.Millisecond, test.description)
+			}
+		}
+	}
+}
+
+func TestSplitDeadlineWithoutDeadline(t *testing.T) {
+	ctx, cancel := splitDeadline(context.Background(), 2)
+	defer cancel()
+
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline, "A context with no deadline shouldn't be given one.")
+}
+
+func TestBadServerResponseSubcodes(t *testing.T) {
+	bidder := newMockTransportBidder(&mixedMultiBidder{}, map[string]adapterstest.MockResponse{
+		"http://bidder.com/not-found": {StatusCode: http.StatusNotFound},
+		"http://bidder.com/down":      {StatusCode: http.StatusServiceUnavailable},
+	})
+
+	testCases := []struct {
+		uri             string
+		expectedSubcode int
+	}{
+		{uri: "http://bidder.com/not-found", expectedSubcode: errortypes.ClientErrorStatusSubcode},
+		{uri: "http://bidder.com/down", expectedSubcode: errortypes.ServerErrorStatusSubcode},
+	}
+	for _, test := range testCases {
+		callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
+			Method: "POST",
+			Uri:    test.uri,
+		}, false)
+		if assert.IsType(t, &errortypes.BadServerResponse{}, callInfo.err, test.uri) {
+			assert.Equal(t, test.expectedSubcode, errortypes.ReadSubcode(callInfo.err), test.uri)
+		}
+	}
+
+	assert.Equal(t, errortypes.UnexpectedStatusSubcode, statusSubcode(http.StatusFound))
+	assert.Equal(t, errortypes.UnexpectedStatusSubcode, statusSubcode(http.StatusContinue))
+}
+
+func TestRequestCompressionThreshold(t *testing.T) {
+	var receivedEncoding string
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedEncoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if receivedEncoding == "gzip" {
+			gzipReader, err := gzip.NewReader(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gzipReader
+		}
+		receivedBody, _ = ioutil.ReadAll(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bidder := &bidderAdapter{
+		Bidder: &mixedMultiBidder{},
+		Client: server.Client(),
+		config: bidderAdapterConfig{
+			GzipRequests:     true,
+			GzipMinBodyBytes: 10,
+		},
+	}
+
+	testCases := []struct {
+		description      string
+		body             string
+		expectedEncoding string
+	}{
+		{description: "Below the threshold", body: `{"id":1}`, expectedEncoding: ""},
+		{description: "Above the threshold", body: `{"id":"a-much-longer-request"}`, expectedEncoding: "gzip"},
+	}
+	for _, test := range testCases {
+		headers := http.Header{}
+		headers.Set("Content-Type", "application/json")
+		callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
+			Method:  "POST",
+			Uri:     server.URL,
+			Body:    []byte(test.body),
+			Headers: headers,
+		}, false)
+
+		assert.NoError(t, callInfo.err, test.description)
+		assert.Equal(t, test.expectedEncoding, receivedEncoding, test.description)
+		assert.Equal(t, test.body, string(receivedBody), test.description)
+		assert.Equal(t, test.body, string(callInfo.request.Body), "%s: debug output should show the uncompressed body.", test.description)
+		assert.Empty(t, headers.Get("Content-Encoding"), "%s: the Bidder's headers should not be modified.", test.description)
+	}
+}
+
+func TestWinNotification(t *testing.T) {
+	var receivedURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedURI = r.URL.RequestURI()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterWinNotification", openrtb_ext.BidderAppnexus, true).Return()
+	notifier := &winNoticeBidder{notificationURI: server.URL + "/win?id=%s"}
+	bidder := &bidderAdapter{
+		Bidder:     notifier,
+		BidderName: openrtb_ext.BidderAppnexus,
+		Client:     server.Client(),
+		me:         metricsMock,
+	}
+
+	bidder.doWinNotification(notifier, &pbsOrtbBid{
+		bid:     &openrtb.Bid{ID: "winning-bid", Price: 2},
+		bidType: openrtb_ext.BidTypeBanner,
+		seat:    "some-buyer",
+	})
+
+	assert.Equal(t, "/win?id=winning-bid", receivedURI)
+	if assert.NotNil(t, notifier.notifiedBid) {
+		assert.Equal(t, "some-buyer", notifier.notifiedBid.Seat)
+		assert.Equal(t, openrtb_ext.BidTypeBanner, notifier.notifiedBid.BidType)
+	}
+	metricsMock.AssertExpectations(t)
+}
+
+func TestConnectionReusedAfterErrorStatus(t *testing.T) {
+	server, newConns := newConnCountingServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("something went wrong"))
+	}))
+	defer server.Close()
+	bidder := &bidderAdapter{
+		Bidder: &goodSingleBidder{},
+		Client: server.Client(),
+		me:     &metricsConf.DummyMetricsEngine{},
+	}
+
+	for i := 0; i < 3; i++ {
+		callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{Method: "POST", Uri: server.URL}, false)
+		assert.IsType(t, &errortypes.BadServerResponse{}, callInfo.err)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(newConns), "Error responses shouldn't stop the connection from being reused.")
+}
+
+func TestDoRequestResponseCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"id":"resp-1"}`))
+	}))
+	defer server.Close()
+	bidder := &bidderAdapter{
+		Bidder: &goodSingleBidder{},
+		Client: server.Client(),
+		me:     &metricsConf.DummyMetricsEngine{},
+		config: bidderAdapterConfig{
+			ResponseCache: newResponseCache(config.ResponseCache{SizeBytes: 1024 * 1024, TTLSeconds: 60}, "test"),
+		},
+	}
+	req := &adapters.RequestData{Method: "POST", Uri: server.URL, Body: []byte(`{"id":"req-1"}`)}
+
+	live := bidder.doRequest(context.Background(), req, false)
+	cached := bidder.doRequest(context.Background(), req, false)
+
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls), "The second request should be answered from the cache.")
+	assert.NoError(t, cached.err)
+	assert.Equal(t, live.response.Body, cached.response.Body)
+	assert.False(t, makeExt(live).Cached)
+	assert.True(t, makeExt(cached).Cached, "Cached responses should be flagged in the debug output.")
+}
+
+func TestWinNotificationReusesConnection(t *testing.T) {
+	server, newConns := newConnCountingServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no such bid"))
+	}))
+	defer server.Close()
+	notifier := &winNoticeBidder{notificationURI: server.URL + "/win?id=%s"}
+	bidder := &bidderAdapter{
+		Bidder: notifier,
+		Client: server.Client(),
+		me:     &metricsConf.DummyMetricsEngine{},
+	}
+
+	for i := 0; i < 3; i++ {
+		bidder.doWinNotification(notifier, &pbsOrtbBid{bid: &openrtb.Bid{ID: "winning-bid"}})
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(newConns), "Win notification responses should be drained so the connection can be reused.")
+}
+
+func TestSyntheticLatencyTriggersTimeout(t *testing.T) {
+	notified := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/timeout" {
+			notified <- struct{}{}
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	bidderImpl := &timeoutNoticeBidder{
+		goodSingleBidder: goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    server.URL + "/bid",
+			},
+		},
+		notificationURI: server.URL + "/timeout",
+	}
+	bidder := &bidderAdapter{
+		Bidder: bidderImpl,
+		Client: server.Client(),
+		me:     &metricsConf.DummyMetricsEngine{},
+		config: bidderAdapterConfig{SyntheticLatency: time.Second},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, errs := bidder.requestBid(ctx, &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.Timeout{}, errs[0])
+	}
+	assert.Nil(t, bidderImpl.httpResponse, "The bid request should never have been answered.")
+	select {
+	case <-notified:
+	case <-time.After(time.Second):
+		t.Error("The timeout notification should have been sent.")
+	}
+}
+
+func TestSyntheticLatencyNeedsChaosTesting(t *testing.T) {
+	cfg := &config.Configuration{
+		Adapters: map[string]config.Adapter{
+			string(openrtb_ext.BidderAppnexus): {SyntheticLatencyMs: 500},
+		},
+	}
+	bidder := adaptBidder(&goodSingleBidder{}, http.DefaultClient, cfg, &metricsConf.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus).(*bidderAdapter)
+	assert.Zero(t, bidder.config.SyntheticLatency)
+
+	cfg.EnableChaosTesting = true
+	bidder = adaptBidder(&goodSingleBidder{}, http.DefaultClient, cfg, &metricsConf.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus).(*bidderAdapter)
+	assert.Equal(t, 500*time.Millisecond, bidder.config.SyntheticLatency)
+}
+
+func TestTimeoutNotificationReusesConnection(t *testing.T) {
+	server, newConns := newConnCountingServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("something went wrong"))
+	}))
+	defer server.Close()
+	notifier := &timeoutNoticeBidder{notificationURI: server.URL + "/timeout"}
+	bidder := &bidderAdapter{
+		Bidder: notifier,
+		Client: server.Client(),
+		me:     &metricsConf.DummyMetricsEngine{},
+	}
+
+	for i := 0; i < 3; i++ {
+		bidder.doTimeoutNotification(notifier, &adapters.RequestData{Method: "POST", Uri: server.URL + "/bid"})
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(newConns), "Timeout notification responses should be closed so the connection can be reused.")
+}
+
+// newConnCountingServer starts a test server which counts the connections opened to it.
+func newConnCountingServer(handler http.Handler) (*httptest.Server, *int32) {
+	var newConns int32
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&newConns, 1)
+		}
+	}
+	server.Start()
+	return server, &newConns
+}
+
+func TestWinNotificationNotWanted(t *testing.T) {
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	notifier := &winNoticeBidder{}
+	bidder := &bidderAdapter{
+		Bidder:     notifier,
+		BidderName: openrtb_ext.BidderAppnexus,
+		me:         metricsMock,
+	}
+
+	bidder.doWinNotification(notifier, &pbsOrtbBid{bid: &openrtb.Bid{ID: "winning-bid"}})
+
+	metricsMock.AssertNotCalled(t, "RecordAdapterWinNotification", openrtb_ext.BidderAppnexus, true)
+	metricsMock.AssertNotCalled(t, "RecordAdapterWinNotification", openrtb_ext.BidderAppnexus, false)
+}
+
+func TestSentBytesMatchWire(t *testing.T) {
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	bidder := &bidderAdapter{
+		Bidder: &mixedMultiBidder{},
+		Client: server.Client(),
+	}
+	// Whitespace, escapes, and bytes which aren't valid UTF-8 must all survive untouched.
+	body := []byte("{ \"id\" : \"\\u00e9\xff\" }\n")
+
+	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
+		Method: "POST",
+		Uri:    server.URL,
+		Body:   body,
+	}, false)
+
+	assert.NoError(t, callInfo.err)
+	assert.Equal(t, body, receivedBody, "The bidder should receive exactly the bytes in the request body.")
+	assert.Equal(t, body, callInfo.sentBytes())
+}
+
+func TestSentBytesWhenCompressed(t *testing.T) {
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedBody, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	bidder := &bidderAdapter{
+		Bidder: &mixedMultiBidder{},
+		Client: server.Client(),
+		config: bidderAdapterConfig{GzipRequests: true},
+	}
+	body := []byte(`{"id":"some-request"}`)
+
+	callInfo := bidder.doRequest(context.Background(), &adapters.RequestData{
+		Method: "POST",
+		Uri:    server.URL,
+		Body:   body,
+	}, false)
+
+	assert.NoError(t, callInfo.err)
+	assert.Equal(t, receivedBody, callInfo.sentBytes(), "sentBytes should return the compressed bytes which went on the wire.")
+	assert.Equal(t, body, callInfo.request.Body)
+}
+
+func TestConnectionTracing(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	bidder := &bidderAdapter{
+		Bidder: &mixedMultiBidder{},
+		Client: server.Client(),
+	}
+	reqData := &adapters.RequestData{
+		Method: "GET",
+		Uri:    server.URL,
+	}
+
+	first := bidder.doRequest(context.Background(), reqData, true)
+	second := bidder.doRequest(context.Background(), reqData, true)
+	untraced := bidder.doRequest(context.Background(), reqData, false)
+
+	assert.NoError(t, first.err)
+	assert.Equal(t, &openrtb_ext.ExtHttpCallConnection{Reused: false, TLSHandshake: true}, first.connection)
+	assert.NoError(t, second.err)
+	assert.Equal(t, &openrtb_ext.ExtHttpCallConnection{Reused: true, TLSHandshake: false}, second.connection)
+	assert.Equal(t, second.connection, makeExt(second).Connection, "The trace should show up in the debug output.")
+	assert.Nil(t, untraced.connection, "Connections should only be traced when asked.")
+}
+
+// newMockTransportBidder builds a bidderAdapter whose HTTP calls are answered by an adapterstest.MockTransport.
+//
+// This stays unexported in a test file: requestBid is unexported, so the adapter it returns can only be driven
+// from inside this package, and non-test code can't import adapterstest without pulling the testing package into the server.
+func newMockTransportBidder(bidder adapters.Bidder, responses map[string]adapterstest.MockResponse) *bidderAdapter {
+	return &bidderAdapter{
+		Bidder: bidder,
+		Client: &http.Client{
+			Transport: &adapterstest.MockTransport{
+				Responses: responses,
+			},
+		},
+		me: &metricsConf.DummyMetricsEngine{},
+	}
+}
+
+// timeoutNoticeBidder sends a GET to notificationURI when one of its requests times out.
+type timeoutNoticeBidder struct {
+	goodSingleBidder
+	notificationURI string
+}
+
+func (bidder *timeoutNoticeBidder) MakeTimeoutNotification(req *adapters.RequestData) (*adapters.RequestData, []error) {
+	return &adapters.RequestData{
+		Method: "GET",
+		Uri:    bidder.notificationURI,
+	}, nil
+}
+
+// winNoticeBidder sends a GET to notificationURI, formatted with the bid ID. It doesn't want notifications if notificationURI is empty.
+type winNoticeBidder struct {
+	notificationURI string
+	notifiedBid     *adapters.TypedBid
+}
+
+func (bidder *winNoticeBidder) MakeRequests(request *openrtb.BidRequest, reqInfo *adapters.ExtraRequestInfo) ([]*adapters.RequestData, []error) {
+	return nil, nil
+}
+
+func (bidder *winNoticeBidder) MakeBids(internalRequest *openrtb.BidRequest, externalRequest *adapters.RequestData, response *adapters.ResponseData) (*adapters.BidderResponse, []error) {
+	return nil, nil
+}
+
+func (bidder *winNoticeBidder) MakeWinNotification(bid *adapters.TypedBid) (*adapters.RequestData, []error) {
+	bidder.notifiedBid = bid
+	if bidder.notificationURI == "" {
+		return nil, nil
+	}
+	return &adapters.RequestData{
+		Method: "GET",
+		Uri:    fmt.Sprintf(bidder.notificationURI, bid.Bid.ID),
+	}, nil
+}
+
 type goodSingleBidder struct {
 	bidRequest   *openrtb.BidRequest
 	httpRequest  *adapters.RequestData
@@ -9,6 +9,7 @@ import (
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/currencies"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"golang.org/x/text/currency"
 )
@@ -28,34 +29,59 @@ type validatedBidder struct {
 	bidder adaptedBidder
 }
 
-func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
-	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo)
-	if validationErrors := removeInvalidBids(request, seatBid); len(validationErrors) > 0 {
+func (v *validatedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (*pbsOrtbSeatBid, []error) {
+	seatBid, errs := v.bidder.requestBid(ctx, request, name, bidAdjustment, conversions, reqInfo, options)
+	if validationErrors := removeInvalidBids(request, seatBid, options.fallbackCurrency); len(validationErrors) > 0 {
 		errs = append(errs, validationErrors...)
 	}
 	return seatBid, errs
 }
 
-// validateBids will run some validation checks on the returned bids and excise any invalid bids
-func removeInvalidBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid) []error {
+func (v *validatedBidder) notifyWin(bid *pbsOrtbBid) {
+	if notifier, ok := v.bidder.(winNotifyingBidder); ok {
+		notifier.notifyWin(bid)
+	}
+}
+
+// validateBids will run some validation checks on the returned bids and excise any invalid bids.
+// Bids in the fallbackCurrency are allowed even if request.cur doesn't list it.
+func removeInvalidBids(request *openrtb.BidRequest, seatBid *pbsOrtbSeatBid, fallbackCurrency string) []error {
 	// Exit early if there is nothing to do.
 	if seatBid == nil || len(seatBid.bids) == 0 {
 		return nil
 	}
 
 	// By design, default currency is USD.
-	if cerr := validateCurrency(request.Cur, seatBid.currency); cerr != nil {
+	allowedCurrencies := request.Cur
+	if fallbackCurrency != "" {
+		if len(allowedCurrencies) == 0 {
+			allowedCurrencies = []string{"USD"}
+		}
+		// Limit the capacity so that append can't write into request.Cur.
+		allowedCurrencies = append(allowedCurrencies[:len(allowedCurrencies):len(allowedCurrencies)], fallbackCurrency)
+	}
+	if cerr := validateCurrency(allowedCurrencies, seatBid.currency); cerr != nil {
 		seatBid.bids = nil
 		return []error{cerr}
 	}
 
+	impIDs := make(map[string]struct{}, len(request.Imp))
+	for _, imp := range request.Imp {
+		impIDs[imp.ID] = struct{}{}
+	}
+
 	errs := make([]error, 0, len(seatBid.bids))
 	validBids := make([]*pbsOrtbBid, 0, len(seatBid.bids))
 	for _, bid := range seatBid.bids {
-		if ok, berr := validateBid(bid); ok {
-			validBids = append(validBids, bid)
-		} else {
+		if ok, berr := validateBid(bid); !ok {
 			errs = append(errs, berr)
+		} else if _, ok := impIDs[bid.bid.ImpID]; !ok {
+			// Bids on imps which weren't in the request can never win, whatever their media type.
+			errs = append(errs, &errortypes.Warning{
+				Message: fmt.Sprintf("Bid \"%s\" was dropped because its impid \"%s\" is not in the request", bid.bid.ID, bid.bid.ImpID),
+			})
+		} else {
+			validBids = append(validBids, bid)
 		}
 	}
 	seatBid.bids = validBids
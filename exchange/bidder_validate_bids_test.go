@@ -7,6 +7,7 @@ import (
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/currencies"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
 )
@@ -42,7 +43,7 @@ func TestAllValidBids(t *testing.T) {
 			},
 		},
 	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{})
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "thisImp"}, {ID: "thatImp"}, {ID: "456"}}}, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
 	assert.Len(t, seatBid.bids, 3)
 	assert.Len(t, errs, 0)
 }
@@ -83,7 +84,7 @@ func TestAllBadBids(t *testing.T) {
 			},
 		},
 	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{})
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "thisImp"}, {ID: "thatImp"}, {ID: "456"}}}, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
 	assert.Len(t, seatBid.bids, 0)
 	assert.Len(t, errs, 5)
 }
@@ -126,7 +127,7 @@ func TestMixedBids(t *testing.T) {
 			},
 		},
 	})
-	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{})
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "thisImp"}, {ID: "thatImp"}, {ID: "456"}}}, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
 	assert.Len(t, seatBid.bids, 2)
 	assert.Len(t, errs, 3)
 }
@@ -243,20 +244,63 @@ func TestCurrencyBids(t *testing.T) {
 		}
 
 		request := &openrtb.BidRequest{
+			Imp: []openrtb.Imp{{ID: "thisImp"}, {ID: "thatImp"}},
 			Cur: tc.brqCur,
 		}
 
-		seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{})
+		seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
 		assert.Len(t, seatBid.bids, expectedValidBids)
 		assert.Len(t, errs, expectedErrs)
 	}
 }
 
+func TestFallbackCurrencyBids(t *testing.T) {
+	bidder := ensureValidBids(&mockAdaptedBidder{
+		bidResponse: &pbsOrtbSeatBid{
+			currency: "EUR",
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "one-bid", ImpID: "thisImp", Price: 0.45, CrID: "thisCreative"}},
+			},
+		},
+	})
+	request := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "thisImp"}}, Cur: []string{"JPY", "GBP"}}
+
+	seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{fallbackCurrency: "EUR"})
+
+	assert.Empty(t, errs)
+	assert.Len(t, seatBid.bids, 1)
+	assert.Equal(t, []string{"JPY", "GBP"}, request.Cur, "The request's currencies should not be modified.")
+}
+
+func TestUnknownImpBids(t *testing.T) {
+	bidder := ensureValidBids(&mockAdaptedBidder{
+		bidResponse: &pbsOrtbSeatBid{
+			bids: []*pbsOrtbBid{
+				{bid: &openrtb.Bid{ID: "known-banner", ImpID: "thisImp", Price: 0.45, CrID: "thisCreative"}, bidType: openrtb_ext.BidTypeBanner},
+				{bid: &openrtb.Bid{ID: "unknown-banner", ImpID: "otherImp", Price: 0.45, CrID: "thisCreative"}, bidType: openrtb_ext.BidTypeBanner},
+				{bid: &openrtb.Bid{ID: "unknown-video", ImpID: "otherImp", Price: 0.45, CrID: "thatCreative"}, bidType: openrtb_ext.BidTypeVideo},
+			},
+		},
+	})
+	request := &openrtb.BidRequest{Imp: []openrtb.Imp{{ID: "thisImp"}}}
+
+	seatBid, errs := bidder.requestBid(context.Background(), request, openrtb_ext.BidderAppnexus, 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, seatBid.bids, 1) {
+		assert.Equal(t, "known-banner", seatBid.bids[0].bid.ID)
+	}
+	if assert.Len(t, errs, 2) {
+		for _, err := range errs {
+			assert.IsType(t, &errortypes.Warning{}, err, "Bids on unknown imps should be dropped with warnings.")
+		}
+	}
+}
+
 type mockAdaptedBidder struct {
 	bidResponse   *pbsOrtbSeatBid
 	errorResponse []error
 }
 
-func (b *mockAdaptedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo) (*pbsOrtbSeatBid, []error) {
+func (b *mockAdaptedBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (*pbsOrtbSeatBid, []error) {
 	return b.bidResponse, b.errorResponse
 }
@@ -0,0 +1,46 @@
+package exchange
+
+import "context"
+
+// callLimiter bounds how many HTTP calls to bidders a single auction may have in flight at once.
+// One is made for each auction, and shared by all of its bidders.
+//
+// A slot is only held for the length of one HTTP call, and acquire gives up when the auction's context ends,
+// so the auction can't deadlock even if it has more bidders than slots.
+//
+// A nil *callLimiter is valid, and never blocks.
+type callLimiter struct {
+	slots chan struct{}
+}
+
+// newCallLimiter returns nil if maxCalls isn't positive.
+func newCallLimiter(maxCalls int) *callLimiter {
+	if maxCalls <= 0 {
+		return nil
+	}
+	return &callLimiter{
+		slots: make(chan struct{}, maxCalls),
+	}
+}
+
+// acquire waits for a free slot. It returns false if the context ended first, in which case
+// release must not be called.
+func (l *callLimiter) acquire(ctx context.Context) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees the slot taken by a successful call to acquire.
+func (l *callLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
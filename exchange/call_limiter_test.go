@@ -0,0 +1,84 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/currencies"
+	metricsConf "github.com/prebid/prebid-server/pbsmetrics/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallLimiterBlocksWhenFull(t *testing.T) {
+	limiter := newCallLimiter(1)
+	assert.True(t, limiter.acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.False(t, limiter.acquire(ctx), "acquire should give up when the context ends.")
+
+	limiter.release()
+	assert.True(t, limiter.acquire(context.Background()), "A released slot should be usable again.")
+}
+
+func TestCallLimiterUnlimited(t *testing.T) {
+	limiter := newCallLimiter(0)
+
+	assert.Nil(t, limiter)
+	for i := 0; i < 10; i++ {
+		assert.True(t, limiter.acquire(context.Background()))
+	}
+	limiter.release()
+}
+
+func TestCallLimiterSharedAcrossBidders(t *testing.T) {
+	var inFlight, maxInFlight, calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// There are more bidders than slots, and each one makes several calls. They should all finish.
+	limiter := newCallLimiter(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		bidder := &bidderAdapter{
+			Bidder: &goodMultiHTTPCallsBidder{
+				httpRequest: []*adapters.RequestData{
+					{Method: "POST", Uri: server.URL},
+					{Method: "POST", Uri: server.URL},
+				},
+				bidResponses: make([]*adapters.BidderResponse, 2),
+			},
+			Client: server.Client(),
+			me:     &metricsConf.DummyMetricsEngine{},
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{callLimiter: limiter})
+			assert.Empty(t, errs)
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 6, atomic.LoadInt32(&calls))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&maxInFlight), "The limiter should allow only one call at a time.")
+}
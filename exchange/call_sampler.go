@@ -0,0 +1,69 @@
+package exchange
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// HTTPCallSink receives a sample of the calls made to the Bidders, described just like the httpcalls
+// in the debug output of test requests. It's meant for shipping real traffic to an out-of-band store.
+//
+// It's called from the goroutines which make the calls, so it must be safe for concurrent use,
+// and should hand the call off rather than block.
+type HTTPCallSink func(bidder openrtb_ext.BidderName, call *openrtb_ext.ExtHttpCall)
+
+var httpCallSink struct {
+	sync.RWMutex
+	sink HTTPCallSink
+}
+
+// RegisterHTTPCallSink sets the sink for the calls sampled by every Bidder, replacing any earlier one.
+// Pass nil to stop sending calls anywhere.
+func RegisterHTTPCallSink(sink HTTPCallSink) {
+	httpCallSink.Lock()
+	defer httpCallSink.Unlock()
+	httpCallSink.sink = sink
+}
+
+func registeredHTTPCallSink() HTTPCallSink {
+	httpCallSink.RLock()
+	defer httpCallSink.RUnlock()
+	return httpCallSink.sink
+}
+
+// callSampler sends a random sample of a Bidder's calls to the registered HTTPCallSink.
+//
+// A nil *callSampler is valid, and never samples anything.
+type callSampler struct {
+	bidder openrtb_ext.BidderName
+	rate   float64
+	// random must be safe to call from many goroutines. The functions in math/rand are.
+	random func() float64
+}
+
+// newCallSampler returns nil if the rate is 0. A rate of 0.001 samples about 1 in 1000 calls.
+func newCallSampler(bidder openrtb_ext.BidderName, rate float64) *callSampler {
+	if rate <= 0 {
+		return nil
+	}
+	return &callSampler{
+		bidder: bidder,
+		rate:   rate,
+		random: rand.Float64,
+	}
+}
+
+// sample sends the call to the sink if it's chosen, and reports whether it was.
+func (s *callSampler) sample(httpInfo *httpCallInfo) bool {
+	if s == nil {
+		return false
+	}
+	sink := registeredHTTPCallSink()
+	if sink == nil || s.random() >= s.rate {
+		return false
+	}
+	sink(s.bidder, makeExt(httpInfo))
+	return true
+}
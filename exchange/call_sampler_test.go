@@ -0,0 +1,74 @@
+package exchange
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/adapters/adapterstest"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallSamplerRate(t *testing.T) {
+	var sampled int
+	RegisterHTTPCallSink(func(bidder openrtb_ext.BidderName, call *openrtb_ext.ExtHttpCall) {
+		sampled++
+	})
+	defer RegisterHTTPCallSink(nil)
+	sampler := newCallSampler("appnexus", 0.01)
+	sampler.random = rand.New(rand.NewSource(1)).Float64
+
+	const calls = 100000
+	for i := 0; i < calls; i++ {
+		sampler.sample(&httpCallInfo{
+			request:  &adapters.RequestData{Uri: "http://bidder.com/bid"},
+			response: &adapters.ResponseData{StatusCode: 200},
+		})
+	}
+
+	assert.InDelta(t, calls/100, sampled, calls/1000, "About 1 in 100 calls should be sampled.")
+}
+
+func TestCallSamplerWithoutSink(t *testing.T) {
+	sampler := newCallSampler("appnexus", 1)
+
+	assert.False(t, sampler.sample(&httpCallInfo{request: &adapters.RequestData{}}))
+}
+
+func TestCallSamplerDisabled(t *testing.T) {
+	sampler := newCallSampler("appnexus", 0)
+
+	assert.Nil(t, sampler)
+	assert.False(t, sampler.sample(&httpCallInfo{request: &adapters.RequestData{}}))
+}
+
+func TestDoRequestSamplesCall(t *testing.T) {
+	var sampledBidders []openrtb_ext.BidderName
+	var sampledCalls []*openrtb_ext.ExtHttpCall
+	RegisterHTTPCallSink(func(bidder openrtb_ext.BidderName, call *openrtb_ext.ExtHttpCall) {
+		sampledBidders = append(sampledBidders, bidder)
+		sampledCalls = append(sampledCalls, call)
+	})
+	defer RegisterHTTPCallSink(nil)
+
+	bidder := newMockTransportBidder(&goodSingleBidder{}, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {StatusCode: 200, Body: `{"id":"resp"}`},
+	})
+	bidder.config.CallSampler = newCallSampler(openrtb_ext.BidderAppnexus, 1)
+
+	bidder.doRequest(context.Background(), &adapters.RequestData{
+		Method: "POST",
+		Uri:    "http://bidder.com/bid",
+		Body:   []byte(`{"id":"req"}`),
+	}, false)
+
+	assert.Equal(t, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, sampledBidders)
+	assert.Equal(t, []*openrtb_ext.ExtHttpCall{{
+		Uri:          "http://bidder.com/bid",
+		RequestBody:  `{"id":"req"}`,
+		ResponseBody: `{"id":"resp"}`,
+		Status:       200,
+	}}, sampledCalls)
+}
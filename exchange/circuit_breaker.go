@@ -0,0 +1,134 @@
+package exchange
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/pbsmetrics"
+)
+
+// circuitBreaker tracks the health of a single bidder's endpoint across auctions.
+//
+// Once FailureThreshold consecutive calls have failed within WindowMillis, the breaker opens and allow()
+// returns false until CooldownMillis have passed. After that, the breaker is half-open: a single probe call
+// is let through. If the probe fails the breaker re-opens for another cooldown, and if it succeeds the
+// breaker closes. A probe which never reports back is replaced by a new one after a cooldown.
+//
+// Every change of state is recorded in the metrics under the bidder's name.
+//
+// A nil *circuitBreaker is valid, and never blocks any calls.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       func() time.Time
+	me        pbsmetrics.MetricsEngine
+	bidder    openrtb_ext.BidderName
+
+	lock  sync.Mutex
+	state pbsmetrics.CircuitBreakerState
+	// failures counts the consecutive failures since firstFailure while the breaker is closed.
+	failures     int
+	firstFailure time.Time
+	// openUntil is when an open breaker becomes half-open.
+	openUntil time.Time
+	// probeStart is when a half-open breaker let its probe through.
+	probeStart time.Time
+}
+
+// newCircuitBreaker returns nil if the config doesn't enable the breaker.
+func newCircuitBreaker(cfg config.CircuitBreaker, me pbsmetrics.MetricsEngine, bidder openrtb_ext.BidderName) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	return &circuitBreaker{
+		threshold: cfg.FailureThreshold,
+		window:    time.Duration(cfg.WindowMillis) * time.Millisecond,
+		cooldown:  time.Duration(cfg.CooldownMillis) * time.Millisecond,
+		now:       time.Now,
+		me:        me,
+		bidder:    bidder,
+		state:     pbsmetrics.CircuitBreakerClosed,
+	}
+}
+
+// allow returns false if calls to the bidder should be skipped right now.
+func (cb *circuitBreaker) allow() bool {
+	if cb == nil {
+		return true
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	now := cb.now()
+	switch cb.state {
+	case pbsmetrics.CircuitBreakerOpen:
+		if now.Before(cb.openUntil) {
+			return false
+		}
+		cb.probeStart = now
+		cb.transition(pbsmetrics.CircuitBreakerHalfOpen)
+		return true
+	case pbsmetrics.CircuitBreakerHalfOpen:
+		if now.Sub(cb.probeStart) < cb.cooldown {
+			return false
+		}
+		cb.probeStart = now
+		return true
+	default:
+		return true
+	}
+}
+
+// recordSuccess closes the breaker, because the endpoint is responding again.
+func (cb *circuitBreaker) recordSuccess() {
+	if cb == nil {
+		return
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	cb.failures = 0
+	cb.transition(pbsmetrics.CircuitBreakerClosed)
+}
+
+// recordFailure counts a failed call, and opens the breaker if there have been too many in a row.
+func (cb *circuitBreaker) recordFailure() {
+	if cb == nil {
+		return
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	now := cb.now()
+	switch cb.state {
+	case pbsmetrics.CircuitBreakerOpen:
+		// This call started before the breaker opened. It doesn't change anything.
+		return
+	case pbsmetrics.CircuitBreakerHalfOpen:
+		// The probe failed, so the endpoint still isn't healthy.
+		cb.openUntil = now.Add(cb.cooldown)
+		cb.transition(pbsmetrics.CircuitBreakerOpen)
+		return
+	}
+	if cb.failures == 0 || now.Sub(cb.firstFailure) > cb.window {
+		cb.failures = 0
+		cb.firstFailure = now
+	}
+	cb.failures++
+	if cb.failures >= cb.threshold {
+		cb.failures = 0
+		cb.openUntil = now.Add(cb.cooldown)
+		cb.transition(pbsmetrics.CircuitBreakerOpen)
+	}
+}
+
+// transition must be called with the lock held.
+func (cb *circuitBreaker) transition(state pbsmetrics.CircuitBreakerState) {
+	if cb.state == state {
+		return
+	}
+	cb.state = state
+	cb.me.RecordAdapterCircuitBreakerTransition(cb.bidder, state)
+}
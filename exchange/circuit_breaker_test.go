@@ -0,0 +1,102 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/prebid/prebid-server/pbsmetrics"
+	metricsConf "github.com/prebid/prebid-server/pbsmetrics/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := newCircuitBreaker(config.CircuitBreaker{}, &metricsConf.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus)
+	assert.Nil(t, cb, "A zero failure_threshold should disable the breaker.")
+
+	cb.recordFailure()
+	assert.True(t, cb.allow(), "A disabled breaker should never block calls.")
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	cb, clock := newTestCircuitBreaker(3)
+
+	cb.recordFailure()
+	cb.recordFailure()
+	assert.True(t, cb.allow(), "The breaker should stay closed below the failure threshold.")
+	cb.recordFailure()
+	assert.False(t, cb.allow(), "The breaker should open once the failure threshold is reached.")
+
+	*clock = clock.Add(time.Minute)
+	assert.True(t, cb.allow(), "The breaker should let calls through once the cooldown has passed.")
+	cb.recordFailure()
+	assert.False(t, cb.allow(), "A failure after the cooldown should re-open the breaker right away.")
+
+	*clock = clock.Add(time.Minute)
+	cb.recordSuccess()
+	cb.recordFailure()
+	assert.True(t, cb.allow(), "A success should close the breaker and reset the failure count.")
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	cb, clock := newTestCircuitBreaker(2)
+
+	cb.recordFailure()
+	*clock = clock.Add(2 * time.Second)
+	cb.recordFailure()
+	assert.True(t, cb.allow(), "Failures further apart than the window should not open the breaker.")
+
+	cb.recordFailure()
+	assert.False(t, cb.allow(), "Failures within the window should open the breaker.")
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	cb, clock := newTestCircuitBreaker(1)
+
+	cb.recordFailure()
+	*clock = clock.Add(time.Minute)
+	assert.True(t, cb.allow(), "The breaker should let a probe through once the cooldown has passed.")
+	assert.False(t, cb.allow(), "Only one probe should be let through while the breaker is half-open.")
+
+	*clock = clock.Add(time.Minute)
+	assert.True(t, cb.allow(), "A probe which never reported back should be replaced after a cooldown.")
+
+	cb.recordSuccess()
+	assert.True(t, cb.allow(), "A successful probe should close the breaker.")
+	assert.True(t, cb.allow(), "A closed breaker should let every call through.")
+}
+
+func TestCircuitBreakerTransitionMetrics(t *testing.T) {
+	metricsMock := &pbsmetrics.MetricsEngineMock{}
+	metricsMock.On("RecordAdapterCircuitBreakerTransition", openrtb_ext.BidderAppnexus, pbsmetrics.CircuitBreakerOpen).Return().Twice()
+	metricsMock.On("RecordAdapterCircuitBreakerTransition", openrtb_ext.BidderAppnexus, pbsmetrics.CircuitBreakerHalfOpen).Return().Twice()
+	metricsMock.On("RecordAdapterCircuitBreakerTransition", openrtb_ext.BidderAppnexus, pbsmetrics.CircuitBreakerClosed).Return().Once()
+	cb, clock := newTestCircuitBreaker(1)
+	cb.me = metricsMock
+
+	cb.recordFailure()
+	cb.recordFailure()
+	*clock = clock.Add(time.Minute)
+	cb.allow()
+	cb.recordFailure()
+	*clock = clock.Add(time.Minute)
+	cb.allow()
+	cb.recordSuccess()
+	cb.recordSuccess()
+
+	metricsMock.AssertExpectations(t)
+}
+
+// newTestCircuitBreaker makes a breaker with a 1 second window and a 30 second cooldown,
+// whose clock only moves when the test changes it.
+func newTestCircuitBreaker(threshold int) (*circuitBreaker, *time.Time) {
+	clock := time.Now()
+	cb := newCircuitBreaker(config.CircuitBreaker{
+		FailureThreshold: threshold,
+		WindowMillis:     1000,
+		CooldownMillis:   30000,
+	}, &metricsConf.DummyMetricsEngine{}, openrtb_ext.BidderAppnexus)
+	cb.now = func() time.Time { return clock }
+	return cb, &clock
+}
@@ -0,0 +1,54 @@
+package exchange
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sync"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// connectionTrace records how the connection for a single call to a bidder was set up.
+// It's only used for test requests, since tracing adds overhead to every call.
+//
+// The Transport may run TLS handshakes on another goroutine, so the fields are locked.
+type connectionTrace struct {
+	lock         sync.Mutex
+	gotConn      bool
+	reused       bool
+	tlsHandshake bool
+}
+
+// withConnectionTrace returns a context which makes the HTTP client report to a new connectionTrace.
+func withConnectionTrace(ctx context.Context) (context.Context, *connectionTrace) {
+	connTrace := &connectionTrace{}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			connTrace.lock.Lock()
+			defer connTrace.lock.Unlock()
+			connTrace.gotConn = true
+			connTrace.reused = info.Reused
+		},
+		TLSHandshakeStart: func() {
+			connTrace.lock.Lock()
+			defer connTrace.lock.Unlock()
+			connTrace.tlsHandshake = true
+		},
+	}), connTrace
+}
+
+// result returns nil if the call never got a connection, or if it wasn't traced at all.
+func (connTrace *connectionTrace) result() *openrtb_ext.ExtHttpCallConnection {
+	if connTrace == nil {
+		return nil
+	}
+	connTrace.lock.Lock()
+	defer connTrace.lock.Unlock()
+	if !connTrace.gotConn {
+		return nil
+	}
+	return &openrtb_ext.ExtHttpCallConnection{
+		Reused:       connTrace.reused,
+		TLSHandshake: connTrace.tlsHandshake,
+	}
+}
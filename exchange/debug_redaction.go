@@ -0,0 +1,57 @@
+package exchange
+
+import (
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// redactedValue replaces the value of each redacted field. It's a JSON string, so the bodies stay valid JSON.
+var redactedValue = []byte(`"[REDACTED]"`)
+
+// debugRedactor masks fields which publishers shouldn't see in the request and response bodies
+// of the debug output, like user IDs.
+//
+// A nil *debugRedactor is valid, and leaves the debug output untouched.
+type debugRedactor struct {
+	paths [][]string
+}
+
+// newDebugRedactor returns nil if there are no paths. Each path is a dot-separated list of object keys, like "user.id".
+func newDebugRedactor(paths []string) *debugRedactor {
+	if len(paths) == 0 {
+		return nil
+	}
+	splitPaths := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		splitPaths = append(splitPaths, strings.Split(path, "."))
+	}
+	return &debugRedactor{
+		paths: splitPaths,
+	}
+}
+
+// redact masks the configured fields in the call's request and response bodies.
+func (r *debugRedactor) redact(call *openrtb_ext.ExtHttpCall) *openrtb_ext.ExtHttpCall {
+	if r == nil {
+		return call
+	}
+	call.RequestBody = r.redactJSON(call.RequestBody)
+	call.ResponseBody = r.redactJSON(call.ResponseBody)
+	return call
+}
+
+// redactJSON leaves bodies which aren't JSON, and fields which don't exist, alone.
+func (r *debugRedactor) redactJSON(body string) string {
+	redacted := []byte(body)
+	for _, path := range r.paths {
+		if _, _, _, err := jsonparser.Get(redacted, path...); err != nil {
+			continue
+		}
+		if masked, err := jsonparser.Set(redacted, redactedValue, path...); err == nil {
+			redacted = masked
+		}
+	}
+	return string(redacted)
+}
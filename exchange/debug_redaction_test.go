@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugRedaction(t *testing.T) {
+	redactor := newDebugRedactor([]string{"user.id", "device.ifa", "id"})
+
+	testCases := []struct {
+		description string
+		body        string
+		expected    string
+	}{
+		{
+			description: "Nested fields",
+			body:        `{"user":{"id":"user-1","yob":1980},"device":{"ifa":"some-ifa"}}`,
+			expected:    `{"user":{"id":"[REDACTED]","yob":1980},"device":{"ifa":"[REDACTED]"}}`,
+		},
+		{
+			description: "Non-string values",
+			body:        `{"id":{"a":[1,2]},"user":{"id":42}}`,
+			expected:    `{"id":"[REDACTED]","user":{"id":"[REDACTED]"}}`,
+		},
+		{
+			description: "Missing fields are not added",
+			body:        `{"user":{"yob":1980}}`,
+			expected:    `{"user":{"yob":1980}}`,
+		},
+		{
+			description: "Bodies which aren't JSON are left alone",
+			body:        `id=user-1`,
+			expected:    `id=user-1`,
+		},
+		{
+			description: "Empty bodies are left alone",
+			body:        ``,
+			expected:    ``,
+		},
+	}
+
+	for _, test := range testCases {
+		call := redactor.redact(&openrtb_ext.ExtHttpCall{
+			RequestBody:  test.body,
+			ResponseBody: test.body,
+		})
+		assert.Equal(t, test.expected, call.RequestBody, "%s: request body", test.description)
+		assert.Equal(t, test.expected, call.ResponseBody, "%s: response body", test.description)
+		if test.body != "" && json.Valid([]byte(test.body)) {
+			assert.True(t, json.Valid([]byte(call.RequestBody)), "%s: redacted JSON should stay valid", test.description)
+		}
+	}
+}
+
+func TestDebugRedactionDisabled(t *testing.T) {
+	redactor := newDebugRedactor(nil)
+	assert.Nil(t, redactor)
+
+	call := &openrtb_ext.ExtHttpCall{RequestBody: `{"user":{"id":"user-1"}}`}
+	assert.Equal(t, `{"user":{"id":"user-1"}}`, redactor.redact(call).RequestBody)
+}
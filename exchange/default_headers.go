@@ -0,0 +1,67 @@
+package exchange
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// defaultHeaders adds the headers configured for all Bidders to their outgoing requests. The values may
+// contain the tokens ${REQUEST_ID} and ${BIDDER}, which are replaced by request.id and the Bidder's name.
+//
+// A nil *defaultHeaders is valid, and adds nothing.
+type defaultHeaders struct {
+	names  []string
+	values []string
+}
+
+// newDefaultHeaders returns nil if there are no headers. The names are canonicalized, since viper lowercases config keys.
+func newDefaultHeaders(headers map[string]string) *defaultHeaders {
+	if len(headers) == 0 {
+		return nil
+	}
+	h := &defaultHeaders{
+		names:  make([]string, 0, len(headers)),
+		values: make([]string, 0, len(headers)),
+	}
+	for name, value := range headers {
+		h.names = append(h.names, http.CanonicalHeaderKey(name))
+		h.values = append(h.values, value)
+	}
+	return h
+}
+
+// apply returns the request with the headers which the Bidder didn't set itself. Headers whose value is
+// empty once the tokens are replaced are skipped. If nothing is added, the original request is returned.
+// Otherwise the headers are copied, since the Bidder may share them between requests.
+func (h *defaultHeaders) apply(req *adapters.RequestData, requestID string, bidder openrtb_ext.BidderName) *adapters.RequestData {
+	if h == nil || req == nil {
+		return req
+	}
+	replacer := strings.NewReplacer("${REQUEST_ID}", requestID, "${BIDDER}", string(bidder))
+	var withHeaders *adapters.RequestData
+	for i, name := range h.names {
+		if req.Headers.Get(name) != "" {
+			continue
+		}
+		value := replacer.Replace(h.values[i])
+		if value == "" {
+			continue
+		}
+		if withHeaders == nil {
+			copied := *req
+			copied.Headers = make(http.Header, len(req.Headers)+len(h.names))
+			for key, values := range req.Headers {
+				copied.Headers[key] = values
+			}
+			withHeaders = &copied
+		}
+		withHeaders.Headers.Set(name, value)
+	}
+	if withHeaders == nil {
+		return req
+	}
+	return withHeaders
+}
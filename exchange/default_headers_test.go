@@ -0,0 +1,73 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/currencies"
+	metricsConf "github.com/prebid/prebid-server/pbsmetrics/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDefaultHeadersDisabled(t *testing.T) {
+	headers := newDefaultHeaders(nil)
+
+	assert.Nil(t, headers)
+	req := &adapters.RequestData{Uri: "http://bidder.com/bid"}
+	assert.True(t, req == headers.apply(req, "request-id", "appnexus"), "A nil *defaultHeaders should return the same request.")
+}
+
+func TestDefaultHeadersApply(t *testing.T) {
+	headers := newDefaultHeaders(map[string]string{
+		"x-request-id":     "${REQUEST_ID}",
+		"x-forwarded-host": "pbs.example.com",
+		"x-trace":          "${BIDDER}/${REQUEST_ID}",
+	})
+	bidderHeaders := http.Header{"X-Forwarded-Host": []string{"bidder.com"}}
+	req := &adapters.RequestData{Uri: "http://bidder.com/bid", Headers: bidderHeaders}
+
+	applied := headers.apply(req, "request-id", "appnexus")
+
+	assert.Equal(t, http.Header{
+		"X-Request-Id":     []string{"request-id"},
+		"X-Forwarded-Host": []string{"bidder.com"},
+		"X-Trace":          []string{"appnexus/request-id"},
+	}, applied.Headers, "The tokens should be replaced, and the Bidder's own headers should win.")
+	assert.Equal(t, http.Header{"X-Forwarded-Host": []string{"bidder.com"}}, bidderHeaders, "The Bidder's headers shouldn't be modified.")
+}
+
+func TestDefaultHeadersSkipsEmptyValues(t *testing.T) {
+	headers := newDefaultHeaders(map[string]string{"x-request-id": "${REQUEST_ID}"})
+	req := &adapters.RequestData{Uri: "http://bidder.com/bid"}
+
+	assert.True(t, req == headers.apply(req, "", "appnexus"), "Nothing should be added if the request has no ID.")
+}
+
+func TestRequestBidDefaultHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	bidder := &bidderAdapter{
+		Bidder: &goodSingleBidder{
+			httpRequest: &adapters.RequestData{
+				Method: "POST",
+				Uri:    server.URL,
+			},
+		},
+		Client: server.Client(),
+		me:     &metricsConf.DummyMetricsEngine{},
+		config: bidderAdapterConfig{DefaultHeaders: newDefaultHeaders(map[string]string{"x-trace": "${BIDDER}-${REQUEST_ID}"})},
+	}
+
+	_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{ID: "request-id"}, "alias", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	assert.Equal(t, "alias-request-id", received.Get("X-Trace"))
+}
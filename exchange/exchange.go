@@ -49,6 +49,14 @@ type exchange struct {
 	UsersyncIfAmbiguous bool
 	defaultTTLs         config.DefaultTTLs
 	enforceCCPA         bool
+	dealTargetingKey    openrtb_ext.TargetingKey
+	// maxBidderCalls limits the in-flight HTTP calls of each auction. 0 means no limit.
+	maxBidderCalls int
+	adomainFilter  config.ADomainFilter
+	// fallbackCurrency is used when bids can't be converted to any currency in request.cur. "" means there isn't one.
+	fallbackCurrency string
+	// bidCallback is given to every bidder. It's nil unless some custom rules need to see each bid.
+	bidCallback bidCallback
 }
 
 // Container to pass out response ext data from the GetAllBids goroutines back into the main thread
@@ -58,6 +66,12 @@ type seatResponseExtra struct {
 	// httpCalls is the list of debugging info. It should only be populated if the request.test == 1.
 	// This will become response.ext.debug.httpcalls.{bidder} on the final Response.
 	HttpCalls []*openrtb_ext.ExtHttpCall
+	// CurrencyConversions is only populated if the request.test == 1.
+	// This will become response.ext.debug.currencyconversions.{bidder} on the final Response.
+	CurrencyConversions []openrtb_ext.ExtCurrencyConversion
+	// Partial is true if some of the Bidder's requests failed while others succeeded.
+	// This will become response.ext.partial.{bidder} on the final Response.
+	Partial bool
 }
 
 type bidResponseWrapper struct {
@@ -69,7 +83,7 @@ type bidResponseWrapper struct {
 func NewExchange(client *http.Client, cache prebid_cache_client.Client, cfg *config.Configuration, metricsEngine pbsmetrics.MetricsEngine, infos adapters.BidderInfos, gDPR gdpr.Permissions, currencyConverter *currencies.RateConverter) Exchange {
 	e := new(exchange)
 
-	e.adapterMap = newAdapterMap(client, cfg, infos)
+	e.adapterMap = newAdapterMap(client, cfg, infos, metricsEngine)
 	e.cache = cache
 	e.cacheTime = time.Duration(cfg.CacheURL.ExpectedTimeMillis) * time.Millisecond
 	e.me = metricsEngine
@@ -78,10 +92,16 @@ func NewExchange(client *http.Client, cache prebid_cache_client.Client, cfg *con
 	e.UsersyncIfAmbiguous = cfg.GDPR.UsersyncIfAmbiguous
 	e.defaultTTLs = cfg.CacheURL.DefaultTTLs
 	e.enforceCCPA = cfg.CCPA.Enforce
+	e.dealTargetingKey = openrtb_ext.TargetingKey(cfg.DealTargetingKeyPrefix)
+	e.maxBidderCalls = cfg.MaxConcurrentBidderCalls
+	e.adomainFilter = cfg.ADomainFilter
+	e.fallbackCurrency = cfg.CurrencyConverter.FallbackCurrency
 	return e
 }
 
 func (e *exchange) HoldAuction(ctx context.Context, bidRequest *openrtb.BidRequest, usersyncs IdFetcher, labels pbsmetrics.Labels, categoriesFetcher *stored_requests.CategoryFetcher, debugLog *DebugLog) (*openrtb.BidResponse, error) {
+	auctionStart := time.Now()
+
 	// Snapshot of resolved bid request for debug if test request
 	resolvedRequest, err := buildResolvedRequest(bidRequest)
 	if err != nil {
@@ -110,6 +130,8 @@ func (e *exchange) HoldAuction(ctx context.Context, bidRequest *openrtb.BidReque
 	shouldCacheBids := false
 	shouldCacheVAST := false
 	var bidAdjustmentFactors map[string]float64
+	var allowedImps map[string][]string
+	var adomainFilterExt *openrtb_ext.ExtRequestPrebidADomainFilter
 	var requestExt openrtb_ext.ExtRequest
 	if len(bidRequest.Ext) > 0 {
 		err := json.Unmarshal(bidRequest.Ext, &requestExt)
@@ -117,6 +139,8 @@ func (e *exchange) HoldAuction(ctx context.Context, bidRequest *openrtb.BidReque
 			return nil, fmt.Errorf("Error decoding Request.ext : %s", err.Error())
 		}
 		bidAdjustmentFactors = requestExt.Prebid.BidAdjustmentFactors
+		allowedImps = requestExt.Prebid.AllowedImps
+		adomainFilterExt = requestExt.Prebid.ADomainFilter
 		if requestExt.Prebid.Cache != nil {
 			shouldCacheBids = requestExt.Prebid.Cache.Bids != nil
 			shouldCacheVAST = requestExt.Prebid.Cache.VastXML != nil
@@ -129,11 +153,16 @@ func (e *exchange) HoldAuction(ctx context.Context, bidRequest *openrtb.BidReque
 				includeBidderKeys: requestExt.Prebid.Targeting.IncludeBidderKeys,
 				includeCacheBids:  shouldCacheBids,
 				includeCacheVast:  shouldCacheVAST,
+				dealKey:           e.dealTargetingKey,
 			}
 			targData.cacheHost, targData.cachePath = e.cache.GetExtCacheData()
 		}
 	}
 
+	// Callers which don't derive their deadline from request.tmax still shouldn't let the auction run past it.
+	ctx, cancelTMax := withTMaxDeadline(ctx, auctionStart, bidRequest.TMax)
+	defer cancelTMax()
+
 	// If we need to cache bids, then it will take some time to call prebid cache.
 	// We should reduce the amount of time the bidders have, to compensate.
 	auctionCtx, cancel := e.makeAuctionContext(ctx, shouldCacheBids) //Why no context for `shouldCacheVast`?
@@ -142,7 +171,9 @@ func (e *exchange) HoldAuction(ctx context.Context, bidRequest *openrtb.BidReque
 	// Get currency rates conversions for the auction
 	conversions := e.currencyConverter.Rates()
 
-	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, cleanRequests, aliases, bidAdjustmentFactors, blabels, conversions)
+	adomainFilter := newADomainFilter(e.adomainFilter, adomainFilterExt)
+
+	adapterBids, adapterExtra, anyBidsReturned := e.getAllBids(auctionCtx, cleanRequests, aliases, bidAdjustmentFactors, allowedImps, adomainFilter, blabels, conversions)
 
 	var auc *auction = nil
 	var bidResponseExt *openrtb_ext.ExtBidResponse = nil
@@ -163,6 +194,7 @@ func (e *exchange) HoldAuction(ctx context.Context, bidRequest *openrtb.BidReque
 		}
 
 		auc = newAuction(adapterBids, len(bidRequest.Imp))
+		e.notifyWinners(auc, aliases)
 
 		if targData != nil {
 			auc.setRoundedPrices(targData.priceGranularity)
@@ -201,6 +233,21 @@ func (e *exchange) HoldAuction(ctx context.Context, bidRequest *openrtb.BidReque
 	return e.buildBidResponse(ctx, liveAdapters, adapterBids, bidRequest, resolvedRequest, adapterExtra, auc, bidResponseExt, errs)
 }
 
+// notifyWinners lets the bidder behind each imp's winning bid know that it won, if it wants to be told.
+func (e *exchange) notifyWinners(auc *auction, aliases map[string]string) {
+	for impID, topBidsPerImp := range auc.winningBidsByBidder {
+		winner := auc.winningBids[impID]
+		for bidderName, topBidPerBidder := range topBidsPerImp {
+			if topBidPerBidder != winner {
+				continue
+			}
+			if notifier, ok := e.adapterMap[resolveBidder(string(bidderName), aliases)].(winNotifyingBidder); ok {
+				notifier.notifyWin(winner)
+			}
+		}
+	}
+}
+
 type DealTierInfo struct {
 	Prefix      string `json:"prefix"`
 	MinDealTier int    `json:"minDealTier"`
@@ -288,13 +335,23 @@ func (e *exchange) makeAuctionContext(ctx context.Context, needsCache bool) (auc
 	return
 }
 
+// withTMaxDeadline returns a context whose deadline is no later than tmax milliseconds after start.
+// If the context already has an earlier deadline, or tmax is not positive, the existing deadline is kept.
+func withTMaxDeadline(ctx context.Context, start time.Time, tmax int64) (context.Context, context.CancelFunc) {
+	if tmax <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, start.Add(time.Duration(tmax)*time.Millisecond))
+}
+
 // This piece sends all the requests to the bidder adapters and gathers the results.
-func (e *exchange) getAllBids(ctx context.Context, cleanRequests map[openrtb_ext.BidderName]*openrtb.BidRequest, aliases map[string]string, bidAdjustments map[string]float64, blabels map[openrtb_ext.BidderName]*pbsmetrics.AdapterLabels, conversions currencies.Conversions) (map[openrtb_ext.BidderName]*pbsOrtbSeatBid, map[openrtb_ext.BidderName]*seatResponseExtra, bool) {
+func (e *exchange) getAllBids(ctx context.Context, cleanRequests map[openrtb_ext.BidderName]*openrtb.BidRequest, aliases map[string]string, bidAdjustments map[string]float64, allowedImps map[string][]string, adomainFilter *adomainFilter, blabels map[openrtb_ext.BidderName]*pbsmetrics.AdapterLabels, conversions currencies.Conversions) (map[openrtb_ext.BidderName]*pbsOrtbSeatBid, map[openrtb_ext.BidderName]*seatResponseExtra, bool) {
 	// Set up pointers to the bid results
 	adapterBids := make(map[openrtb_ext.BidderName]*pbsOrtbSeatBid, len(cleanRequests))
 	adapterExtra := make(map[openrtb_ext.BidderName]*seatResponseExtra, len(cleanRequests))
 	chBids := make(chan *bidResponseWrapper, len(cleanRequests))
 	bidsFound := false
+	limiter := newCallLimiter(e.maxBidderCalls)
 
 	for bidderName, req := range cleanRequests {
 		// Here we actually call the adapters and collect the bids.
@@ -319,7 +376,19 @@ func (e *exchange) getAllBids(ctx context.Context, cleanRequests map[openrtb_ext
 			}
 			var reqInfo adapters.ExtraRequestInfo
 			reqInfo.PbsEntryPoint = bidlabels.RType
-			bids, err := e.adapterMap[coreBidder].requestBid(ctx, request, aName, adjustmentFactor, conversions, &reqInfo)
+			options := bidRequestOptions{
+				callLimiter:      limiter,
+				adomainFilter:    adomainFilter,
+				bidCallback:      e.bidCallback,
+				fallbackCurrency: e.fallbackCurrency,
+			}
+			if impIDs, ok := allowedImps[string(aName)]; ok {
+				options.allowedImps = make(map[string]bool, len(impIDs))
+				for _, impID := range impIDs {
+					options.allowedImps[impID] = true
+				}
+			}
+			bids, err := e.adapterMap[coreBidder].requestBid(ctx, request, aName, adjustmentFactor, conversions, &reqInfo, options)
 
 			// Add in time reporting
 			elapsed := time.Since(start)
@@ -329,6 +398,8 @@ func (e *exchange) getAllBids(ctx context.Context, cleanRequests map[openrtb_ext
 			ae.ResponseTimeMillis = int(elapsed / time.Millisecond)
 			if bids != nil {
 				ae.HttpCalls = bids.httpCalls
+				ae.CurrencyConversions = bids.conversions
+				ae.Partial = bids.partial
 			}
 
 			// Timing statistics
@@ -419,6 +490,7 @@ func errsToBidderErrors(errs []error) []openrtb_ext.ExtBidderError {
 	serr := make([]openrtb_ext.ExtBidderError, len(errs))
 	for i := 0; i < len(errs); i++ {
 		serr[i].Code = errortypes.ReadCode(errs[i])
+		serr[i].Subcode = errortypes.ReadSubcode(errs[i])
 		serr[i].Message = errs[i].Error()
 	}
 	return serr
@@ -440,8 +512,11 @@ func (e *exchange) buildBidResponse(ctx context.Context, liveAdapters []openrtb_
 	for _, a := range liveAdapters {
 		//while processing every single bib, do we need to handle categories here?
 		if adapterBids[a] != nil && len(adapterBids[a].bids) > 0 {
-			sb := e.makeSeatBid(adapterBids[a], a, adapterExtra, auc)
-			seatBids = append(seatBids, *sb)
+			seats, bidsBySeat := groupBidsBySeat(adapterBids[a].bids, a)
+			for _, seat := range seats {
+				sb := e.makeSeatBid(adapterBids[a], seat, bidsBySeat[seat], a, adapterExtra, auc)
+				seatBids = append(seatBids, *sb)
+			}
 			bidResponse.Cur = adapterBids[a].currency
 		}
 	}
@@ -655,6 +730,12 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*pb
 
 		if req.Test == 1 {
 			bidResponseExt.Debug.HttpCalls[bidderName] = responseExtra.HttpCalls
+			if len(responseExtra.CurrencyConversions) > 0 {
+				if bidResponseExt.Debug.CurrencyConversions == nil {
+					bidResponseExt.Debug.CurrencyConversions = make(map[openrtb_ext.BidderName][]openrtb_ext.ExtCurrencyConversion)
+				}
+				bidResponseExt.Debug.CurrencyConversions[bidderName] = responseExtra.CurrencyConversions
+			}
 		}
 		// Only make an entry for bidder errors if the bidder reported any.
 		if len(responseExtra.Errors) > 0 {
@@ -664,6 +745,12 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*pb
 			bidResponseExt.Errors[openrtb_ext.PrebidExtKey] = errsToBidderErrors(errList)
 		}
 		bidResponseExt.ResponseTimeMillis[bidderName] = responseExtra.ResponseTimeMillis
+		if responseExtra.Partial {
+			if bidResponseExt.Partial == nil {
+				bidResponseExt.Partial = make(map[openrtb_ext.BidderName]bool)
+			}
+			bidResponseExt.Partial[bidderName] = true
+		}
 		// Defering the filling of bidResponseExt.Usersync[bidderName] until later
 
 	}
@@ -672,9 +759,27 @@ func (e *exchange) makeExtBidResponse(adapterBids map[openrtb_ext.BidderName]*pb
 
 // Return an openrtb seatBid for a bidder
 // BuildBidResponse is responsible for ensuring nil bid seatbids are not included
-func (e *exchange) makeSeatBid(adapterBid *pbsOrtbSeatBid, adapter openrtb_ext.BidderName, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, auc *auction) *openrtb.SeatBid {
+// groupBidsBySeat splits a Bidder's bids by the seat which the Bidder reported for them. Bids without a seat
+// are grouped under the Bidder's name. The seats are returned in the order in which they first appear.
+func groupBidsBySeat(bids []*pbsOrtbBid, adapter openrtb_ext.BidderName) ([]string, map[string][]*pbsOrtbBid) {
+	seats := make([]string, 0, 1)
+	bidsBySeat := make(map[string][]*pbsOrtbBid, 1)
+	for _, bid := range bids {
+		seat := bid.seat
+		if seat == "" {
+			seat = adapter.String()
+		}
+		if _, ok := bidsBySeat[seat]; !ok {
+			seats = append(seats, seat)
+		}
+		bidsBySeat[seat] = append(bidsBySeat[seat], bid)
+	}
+	return seats, bidsBySeat
+}
+
+func (e *exchange) makeSeatBid(adapterBid *pbsOrtbSeatBid, seat string, bids []*pbsOrtbBid, adapter openrtb_ext.BidderName, adapterExtra map[openrtb_ext.BidderName]*seatResponseExtra, auc *auction) *openrtb.SeatBid {
 	seatBid := new(openrtb.SeatBid)
-	seatBid.Seat = adapter.String()
+	seatBid.Seat = seat
 	// Prebid cannot support roadblocking
 	seatBid.Group = 0
 
@@ -695,7 +800,7 @@ func (e *exchange) makeSeatBid(adapterBid *pbsOrtbSeatBid, adapter openrtb_ext.B
 	}
 
 	var errList []error
-	seatBid.Bid, errList = e.makeBid(adapterBid.bids, adapter, auc)
+	seatBid.Bid, errList = e.makeBid(bids, adapter, auc)
 	if len(errList) > 0 {
 		adapterExtra[adapter].Errors = append(adapterExtra[adapter].Errors, errsToBidderErrors(errList)...)
 	}
@@ -714,6 +819,7 @@ func (e *exchange) makeBid(Bids []*pbsOrtbBid, adapter openrtb_ext.BidderName, a
 				Targeting: thisBid.bidTargets,
 				Type:      thisBid.bidType,
 				Video:     thisBid.bidVideo,
+				HttpCall:  thisBid.httpCall,
 			},
 		}
 		if cacheInfo, found := e.getBidCacheInfo(thisBid, auc); found {
@@ -17,6 +17,7 @@ import (
 
 	"github.com/prebid/prebid-server/adapters"
 	"github.com/prebid/prebid-server/currencies"
+	"github.com/prebid/prebid-server/errortypes"
 	"github.com/prebid/prebid-server/prebid_cache_client"
 	"github.com/prebid/prebid-server/stored_requests"
 	"github.com/prebid/prebid-server/stored_requests/backends/file_fetcher"
@@ -695,6 +696,147 @@ func TestTimeoutComputation(t *testing.T) {
 	}
 }
 
+func TestWithTMaxDeadline(t *testing.T) {
+	ctx, cancel := withTMaxDeadline(context.Background(), time.Now(), 0)
+	defer cancel()
+	_, hasDeadline := ctx.Deadline()
+	assert.False(t, hasDeadline, "A zero tmax should not add a deadline.")
+
+	// The auction started a while ago, so tmax must be measured from then rather than from now.
+	start := time.Now().Add(-50 * time.Millisecond)
+	ctx, cancel = withTMaxDeadline(context.Background(), start, 100)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	assert.Equal(t, start.Add(100*time.Millisecond), deadline, "tmax should be counted from the start of the auction.")
+
+	parentDeadline := start.Add(80 * time.Millisecond)
+	parent, parentCancel := context.WithDeadline(context.Background(), parentDeadline)
+	defer parentCancel()
+	ctx, cancel = withTMaxDeadline(parent, start, 100)
+	defer cancel()
+	deadline, _ = ctx.Deadline()
+	assert.Equal(t, parentDeadline, deadline, "A tmax longer than the existing deadline should not extend it.")
+}
+
+// TestTMaxBidderDeadline makes sure that the bidders get request.tmax minus the time reserved for prebid cache,
+// even if the caller of HoldAuction didn't set a deadline.
+func TestTMaxBidderDeadline(t *testing.T) {
+	bidder := &deadlineCapturingBidder{}
+	ex := &exchange{
+		adapterMap:        map[openrtb_ext.BidderName]adaptedBidder{openrtb_ext.BidderAppnexus: bidder},
+		me:                &metricsConf.DummyMetricsEngine{},
+		cache:             &wellBehavedCache{},
+		cacheTime:         10 * time.Millisecond,
+		gDPR:              gdpr.AlwaysAllow{},
+		currencyConverter: currencies.NewRateConverterDefault(),
+	}
+	req := &openrtb.BidRequest{
+		Imp: []openrtb.Imp{{
+			ID:  "some-imp",
+			Ext: json.RawMessage(`{"appnexus":{"placementId":1}}`),
+		}},
+		Site: &openrtb.Site{},
+		TMax: 100,
+		Ext:  json.RawMessage(`{"prebid":{"targeting":{},"cache":{"bids":{}}}}`),
+	}
+
+	before := time.Now()
+	_, err := ex.HoldAuction(context.Background(), req, &emptyUsersync{}, pbsmetrics.Labels{}, nil, nil)
+	after := time.Now()
+	assert.NoError(t, err)
+
+	if assert.True(t, bidder.hasDeadline, "The bidder context should have a deadline.") {
+		assert.False(t, bidder.deadline.Before(before.Add(90*time.Millisecond)), "The bidder deadline is too early.")
+		assert.False(t, bidder.deadline.After(after.Add(90*time.Millisecond)), "The bidder deadline should leave time for prebid cache.")
+	}
+}
+
+type deadlineCapturingBidder struct {
+	deadline    time.Time
+	hasDeadline bool
+}
+
+func (b *deadlineCapturingBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (*pbsOrtbSeatBid, []error) {
+	b.deadline, b.hasDeadline = ctx.Deadline()
+	return &pbsOrtbSeatBid{}, nil
+}
+
+func TestErrsToBidderErrors(t *testing.T) {
+	bidderErrs := errsToBidderErrors([]error{
+		adapters.MalformedResponse("unexpected end of JSON input"),
+		&errortypes.Timeout{Message: "timed out"},
+	})
+
+	assert.Equal(t, []openrtb_ext.ExtBidderError{
+		{Code: errortypes.BadServerResponseErrorCode, Subcode: errortypes.MalformedResponseSubcode, Message: "unexpected end of JSON input"},
+		{Code: errortypes.TimeoutErrorCode, Message: "timed out"},
+	}, bidderErrs)
+}
+
+func TestGroupBidsBySeat(t *testing.T) {
+	bidA := &pbsOrtbBid{bid: &openrtb.Bid{ID: "a"}, seat: "demand-source-1"}
+	bidB := &pbsOrtbBid{bid: &openrtb.Bid{ID: "b"}}
+	bidC := &pbsOrtbBid{bid: &openrtb.Bid{ID: "c"}, seat: "demand-source-1"}
+
+	seats, bidsBySeat := groupBidsBySeat([]*pbsOrtbBid{bidA, bidB, bidC}, openrtb_ext.BidderAppnexus)
+
+	assert.Equal(t, []string{"demand-source-1", "appnexus"}, seats)
+	assert.Equal(t, []*pbsOrtbBid{bidA, bidC}, bidsBySeat["demand-source-1"])
+	assert.Equal(t, []*pbsOrtbBid{bidB}, bidsBySeat["appnexus"], "Bids without a seat should use the bidder name.")
+}
+
+func TestDebugCurrencyConversions(t *testing.T) {
+	e := new(exchange)
+	conversions := []openrtb_ext.ExtCurrencyConversion{{From: "EUR", To: "USD", Rate: 1.1}}
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{
+		openrtb_ext.BidderAppnexus: {CurrencyConversions: conversions},
+		openrtb_ext.BidderRubicon:  {},
+	}
+
+	ext := e.makeExtBidResponse(nil, adapterExtra, &openrtb.BidRequest{Test: 1}, json.RawMessage(`{}`), nil)
+
+	if assert.NotNil(t, ext.Debug) {
+		assert.Equal(t, map[openrtb_ext.BidderName][]openrtb_ext.ExtCurrencyConversion{
+			openrtb_ext.BidderAppnexus: conversions,
+		}, ext.Debug.CurrencyConversions, "Only bidders whose prices were converted should be listed.")
+	}
+}
+
+func TestPartialSeats(t *testing.T) {
+	e := new(exchange)
+	adapterExtra := map[openrtb_ext.BidderName]*seatResponseExtra{
+		openrtb_ext.BidderAppnexus: {Partial: true},
+		openrtb_ext.BidderRubicon:  {},
+	}
+
+	ext := e.makeExtBidResponse(nil, adapterExtra, &openrtb.BidRequest{}, json.RawMessage(`{}`), nil)
+
+	assert.Equal(t, map[openrtb_ext.BidderName]bool{openrtb_ext.BidderAppnexus: true}, ext.Partial, "Only partial seats should be listed.")
+}
+
+func TestNotifyWinners(t *testing.T) {
+	appnexusBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "appnexus-bid", ImpID: "imp-1", Price: 2}}
+	aliasBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "alias-bid", ImpID: "imp-1", Price: 1}}
+	rubiconBid := &pbsOrtbBid{bid: &openrtb.Bid{ID: "rubicon-bid", ImpID: "imp-2", Price: 1}}
+	appnexus := &winRecordingBidder{}
+	rubicon := &winRecordingBidder{}
+	e := &exchange{
+		adapterMap: map[openrtb_ext.BidderName]adaptedBidder{
+			openrtb_ext.BidderAppnexus: ensureValidBids(appnexus),
+			openrtb_ext.BidderRubicon:  rubicon,
+		},
+	}
+	auc := newAuction(map[openrtb_ext.BidderName]*pbsOrtbSeatBid{
+		openrtb_ext.BidderAppnexus: {bids: []*pbsOrtbBid{appnexusBid}},
+		"rubiconAlias":             {bids: []*pbsOrtbBid{aliasBid, rubiconBid}},
+	}, 2)
+
+	e.notifyWinners(auc, map[string]string{"rubiconAlias": string(openrtb_ext.BidderRubicon)})
+
+	assert.Equal(t, []*pbsOrtbBid{appnexusBid}, appnexus.wins, "Winners should be notified through the bid validation wrapper.")
+	assert.Equal(t, []*pbsOrtbBid{rubiconBid}, rubicon.wins, "Aliases should be notified through their core bidder, and only about the bids which won.")
+}
+
 // TestExchangeJSON executes tests for all the *.json files in exchangetest.
 func TestExchangeJSON(t *testing.T) {
 	if specFiles, err := ioutil.ReadDir("./exchangetest"); err == nil {
@@ -1681,7 +1823,7 @@ type validatingBidder struct {
 	mockResponses map[string]bidderResponse
 }
 
-func (b *validatingBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo) (seatBid *pbsOrtbSeatBid, errs []error) {
+func (b *validatingBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (seatBid *pbsOrtbSeatBid, errs []error) {
 	if expectedRequest, ok := b.expectations[string(name)]; ok {
 		if expectedRequest != nil {
 			if expectedRequest.BidAdjustment != bidAdjustment {
@@ -1841,8 +1983,21 @@ func (e *mockUsersync) GetId(bidder openrtb_ext.BidderName) (id string, exists b
 	return
 }
 
+// winRecordingBidder remembers which of its bids it was told had won.
+type winRecordingBidder struct {
+	wins []*pbsOrtbBid
+}
+
+func (b *winRecordingBidder) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (*pbsOrtbSeatBid, []error) {
+	return nil, nil
+}
+
+func (b *winRecordingBidder) notifyWin(bid *pbsOrtbBid) {
+	b.wins = append(b.wins, bid)
+}
+
 type panicingAdapter struct{}
 
-func (panicingAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo) (posb *pbsOrtbSeatBid, errs []error) {
+func (panicingAdapter) requestBid(ctx context.Context, request *openrtb.BidRequest, name openrtb_ext.BidderName, bidAdjustment float64, conversions currencies.Conversions, reqInfo *adapters.ExtraRequestInfo, options bidRequestOptions) (posb *pbsOrtbSeatBid, errs []error) {
 	panic("Panic! Panic! The world is ending!")
 }
@@ -0,0 +1,69 @@
+package exchange
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/prebid/prebid-server/adapters"
+)
+
+// requestMacros substitutes the ${NAME} tokens configured for a Bidder into its outgoing requests.
+//
+// A nil *requestMacros is valid, and leaves all requests untouched.
+type requestMacros struct {
+	uriReplacer  *strings.Replacer
+	bodyReplacer *strings.Replacer
+}
+
+// newRequestMacros returns nil if there are no macros. Since viper lowercases config keys,
+// macro names are upper-cased to build the tokens.
+func newRequestMacros(macros map[string]string) *requestMacros {
+	if len(macros) == 0 {
+		return nil
+	}
+	uriPairs := make([]string, 0, 2*len(macros))
+	bodyPairs := make([]string, 0, 2*len(macros))
+	for name, value := range macros {
+		token := "${" + strings.ToUpper(name) + "}"
+		uriPairs = append(uriPairs, token, url.QueryEscape(value))
+		bodyPairs = append(bodyPairs, token, jsonEscape(value))
+	}
+	return &requestMacros{
+		uriReplacer:  strings.NewReplacer(uriPairs...),
+		bodyReplacer: strings.NewReplacer(bodyPairs...),
+	}
+}
+
+// apply returns the request with its macros replaced. If neither the URI nor the body contain any tokens,
+// the original request is returned. Otherwise, a copy is made so that the Bidder's own data isn't changed.
+func (m *requestMacros) apply(req *adapters.RequestData) *adapters.RequestData {
+	if m == nil {
+		return req
+	}
+	uriHasTokens := strings.Contains(req.Uri, "${")
+	bodyHasTokens := bytes.Contains(req.Body, []byte("${"))
+	if !uriHasTokens && !bodyHasTokens {
+		return req
+	}
+
+	replaced := *req
+	if uriHasTokens {
+		replaced.Uri = m.uriReplacer.Replace(req.Uri)
+	}
+	if bodyHasTokens {
+		replaced.Body = []byte(m.bodyReplacer.Replace(string(req.Body)))
+	}
+	return &replaced
+}
+
+// jsonEscape escapes the value so that it can be placed inside a JSON string.
+func jsonEscape(value string) string {
+	var buffer bytes.Buffer
+	enc := json.NewEncoder(&buffer)
+	enc.SetEscapeHTML(false)
+	enc.Encode(value)
+	escaped := strings.TrimSuffix(buffer.String(), "\n")
+	return escaped[1 : len(escaped)-1]
+}
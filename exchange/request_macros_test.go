@@ -0,0 +1,35 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMacros(t *testing.T) {
+	macros := newRequestMacros(map[string]string{
+		"publisher_tag": `tag "1"&2`,
+	})
+
+	req := &adapters.RequestData{
+		Uri:  "http://bidder.com/bid?tag=${PUBLISHER_TAG}",
+		Body: []byte(`{"tag":"${PUBLISHER_TAG}","other":"${UNKNOWN}"}`),
+	}
+	replaced := macros.apply(req)
+
+	assert.Equal(t, "http://bidder.com/bid?tag=tag+%221%22%262", replaced.Uri, "URI values should be URL-escaped.")
+	assert.Equal(t, `{"tag":"tag \"1\"&2","other":"${UNKNOWN}"}`, string(replaced.Body), "Body values should be JSON-escaped.")
+	assert.Equal(t, "http://bidder.com/bid?tag=${PUBLISHER_TAG}", req.Uri, "The bidder's own request should not be changed.")
+}
+
+func TestRequestMacrosWithoutTokens(t *testing.T) {
+	macros := newRequestMacros(map[string]string{"publisher_tag": "1"})
+	req := &adapters.RequestData{
+		Uri:  "http://bidder.com/bid",
+		Body: []byte(`{"tag":"$PUBLISHER_TAG"}`),
+	}
+
+	assert.True(t, req == macros.apply(req), "Requests without macros should be passed through untouched.")
+	assert.True(t, req == (*requestMacros)(nil).apply(req), "Bidders without macros should be passed through untouched.")
+}
@@ -0,0 +1,42 @@
+package exchange
+
+import (
+	"math/rand"
+
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// requestSampler logs the URI and body of a random sample of a Bidder's outgoing requests, for audits.
+//
+// A nil *requestSampler is valid, and never logs anything.
+type requestSampler struct {
+	bidder openrtb_ext.BidderName
+	rate   float64
+	// random must be safe to call from many goroutines. The functions in math/rand are.
+	random func() float64
+	logf   func(format string, args ...interface{})
+}
+
+// newRequestSampler returns nil if the rate is 0. A rate of 0.001 logs about 1 in 1000 requests.
+func newRequestSampler(bidder openrtb_ext.BidderName, rate float64) *requestSampler {
+	if rate <= 0 {
+		return nil
+	}
+	return &requestSampler{
+		bidder: bidder,
+		rate:   rate,
+		random: rand.Float64,
+		logf:   glog.Infof,
+	}
+}
+
+// sample logs the request if it's chosen, and reports whether it was.
+func (s *requestSampler) sample(req *adapters.RequestData) bool {
+	if s == nil || s.random() >= s.rate {
+		return false
+	}
+	s.logf("Sampled request to bidder %s: %s %s %s", s.bidder, req.Method, req.Uri, req.Body)
+	return true
+}
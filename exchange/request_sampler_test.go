@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestSamplerRate(t *testing.T) {
+	sampler := newRequestSampler("appnexus", 0.01)
+	sampler.random = rand.New(rand.NewSource(1)).Float64
+	var logged int
+	sampler.logf = func(format string, args ...interface{}) {
+		logged++
+	}
+
+	const calls = 100000
+	for i := 0; i < calls; i++ {
+		sampler.sample(&adapters.RequestData{Method: "POST", Uri: "http://bidder.com/bid", Body: []byte("{}")})
+	}
+
+	assert.InDelta(t, calls/100, logged, calls/1000, "About 1 in 100 requests should be logged.")
+}
+
+func TestRequestSamplerLogsRequest(t *testing.T) {
+	sampler := newRequestSampler("appnexus", 1)
+	var message string
+	sampler.logf = func(format string, args ...interface{}) {
+		message = fmt.Sprintf(format, args...)
+	}
+
+	assert.True(t, sampler.sample(&adapters.RequestData{Method: "POST", Uri: "http://bidder.com/bid", Body: []byte(`{"id":"req-1"}`)}))
+	assert.Equal(t, `Sampled request to bidder appnexus: POST http://bidder.com/bid {"id":"req-1"}`, message)
+}
+
+func TestRequestSamplerDisabled(t *testing.T) {
+	sampler := newRequestSampler("appnexus", 0)
+
+	assert.Nil(t, sampler)
+	assert.False(t, sampler.sample(&adapters.RequestData{}))
+}
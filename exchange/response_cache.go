@@ -0,0 +1,88 @@
+package exchange
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sort"
+
+	"github.com/coocood/freecache"
+	"github.com/golang/glog"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// responseCache remembers a Bidder's successful responses, so that an identical request made within
+// the TTL can be answered without calling the Bidder again.
+//
+// A nil *responseCache is valid, and never caches anything.
+type responseCache struct {
+	cache      *freecache.Cache
+	ttlSeconds int
+}
+
+// newResponseCache returns nil if the cache isn't enabled in the config.
+func newResponseCache(cfg config.ResponseCache, bidder openrtb_ext.BidderName) *responseCache {
+	if cfg.SizeBytes <= 0 {
+		return nil
+	}
+	glog.Infof("Caching responses from %s. Max size: %d bytes. TTL: %d seconds.", bidder, cfg.SizeBytes, cfg.TTLSeconds)
+	return &responseCache{
+		cache:      freecache.NewCache(cfg.SizeBytes),
+		ttlSeconds: cfg.TTLSeconds,
+	}
+}
+
+// get returns the cached response to the request, or nil if there isn't one.
+func (c *responseCache) get(req *adapters.RequestData) *adapters.ResponseData {
+	if c == nil {
+		return nil
+	}
+	data, err := c.cache.Get(responseCacheKey(req))
+	if err != nil {
+		return nil
+	}
+	var resp adapters.ResponseData
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil
+	}
+	return &resp
+}
+
+// set caches the response to the request. Responses which are too large for the cache are skipped.
+func (c *responseCache) set(req *adapters.RequestData, resp *adapters.ResponseData) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	c.cache.Set(responseCacheKey(req), data, c.ttlSeconds)
+}
+
+// responseCacheKey hashes everything which the Bidder's server can see in the request.
+func responseCacheKey(req *adapters.RequestData) []byte {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(req.Uri))
+	hash.Write([]byte{0})
+
+	headerNames := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		hash.Write([]byte(name))
+		for _, value := range req.Headers[name] {
+			hash.Write([]byte{0})
+			hash.Write([]byte(value))
+		}
+		hash.Write([]byte{0})
+	}
+
+	hash.Write(req.Body)
+	return hash.Sum(nil)
+}
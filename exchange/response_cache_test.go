@@ -0,0 +1,57 @@
+package exchange
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCacheHit(t *testing.T) {
+	cache := newResponseCache(config.ResponseCache{SizeBytes: 1024 * 1024, TTLSeconds: 60}, "appnexus")
+	req := &adapters.RequestData{Method: "POST", Uri: "http://bidder.com/bid", Body: []byte(`{"id":"req-1"}`)}
+	resp := &adapters.ResponseData{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"id":"resp-1"}`),
+		Headers:    http.Header{"Content-Type": []string{"application/json"}},
+	}
+
+	assert.Nil(t, cache.get(req), "Nothing should be cached yet.")
+	cache.set(req, resp)
+	assert.Equal(t, resp, cache.get(req))
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	base := &adapters.RequestData{
+		Method:  "POST",
+		Uri:     "http://bidder.com/bid",
+		Body:    []byte(`{"id":"req-1"}`),
+		Headers: http.Header{"X-Key": []string{"a"}},
+	}
+	testCases := []struct {
+		description string
+		req         *adapters.RequestData
+	}{
+		{description: "Different method", req: &adapters.RequestData{Method: "GET", Uri: base.Uri, Body: base.Body, Headers: base.Headers}},
+		{description: "Different URI", req: &adapters.RequestData{Method: base.Method, Uri: "http://bidder.com/other", Body: base.Body, Headers: base.Headers}},
+		{description: "Different body", req: &adapters.RequestData{Method: base.Method, Uri: base.Uri, Body: []byte(`{"id":"req-2"}`), Headers: base.Headers}},
+		{description: "Different headers", req: &adapters.RequestData{Method: base.Method, Uri: base.Uri, Body: base.Body, Headers: http.Header{"X-Key": []string{"b"}}}},
+	}
+	for _, test := range testCases {
+		assert.NotEqual(t, responseCacheKey(base), responseCacheKey(test.req), test.description)
+	}
+
+	copied := &adapters.RequestData{Method: base.Method, Uri: base.Uri, Body: []byte(`{"id":"req-1"}`), Headers: http.Header{"X-Key": []string{"a"}}}
+	assert.Equal(t, responseCacheKey(base), responseCacheKey(copied), "Identical requests should share a key.")
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	cache := newResponseCache(config.ResponseCache{}, "appnexus")
+	req := &adapters.RequestData{Method: "POST", Uri: "http://bidder.com/bid"}
+
+	assert.Nil(t, cache)
+	cache.set(req, &adapters.ResponseData{StatusCode: http.StatusOK})
+	assert.Nil(t, cache.get(req))
+}
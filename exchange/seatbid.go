@@ -6,3 +6,52 @@ import "encoding/json"
 type ExtSeatBid struct {
 	Bidder json.RawMessage `json:"bidder,omitempty"`
 }
+
+// setExtField sets key in the seat's ext to the JSON encoding of value. Any other keys in the ext are kept.
+// If the ext is empty, a new JSON object is created for it.
+func (s *pbsOrtbSeatBid) setExtField(key string, value interface{}) error {
+	fields, err := s.extFields()
+	if err != nil {
+		return err
+	}
+	rawValue, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage, 1)
+	}
+	fields[key] = rawValue
+
+	ext, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	s.ext = ext
+	return nil
+}
+
+// getExtField decodes key from the seat's ext into value. It returns false if the ext doesn't have that key.
+func (s *pbsOrtbSeatBid) getExtField(key string, value interface{}) (bool, error) {
+	fields, err := s.extFields()
+	if err != nil {
+		return false, err
+	}
+	rawValue, ok := fields[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(rawValue, value)
+}
+
+// extFields returns nil if the ext is empty or null.
+func (s *pbsOrtbSeatBid) extFields() (map[string]json.RawMessage, error) {
+	if len(s.ext) == 0 {
+		return nil, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(s.ext, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
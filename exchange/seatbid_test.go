@@ -0,0 +1,53 @@
+package exchange
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetExtField(t *testing.T) {
+	testCases := []struct {
+		description string
+		ext         json.RawMessage
+		expectedExt string
+	}{
+		{description: "Empty ext", ext: nil, expectedExt: `{"responsetimemillis":12}`},
+		{description: "Null ext", ext: json.RawMessage(`null`), expectedExt: `{"responsetimemillis":12}`},
+		{description: "Existing keys are kept", ext: json.RawMessage(`{"debug":true}`), expectedExt: `{"debug":true,"responsetimemillis":12}`},
+		{description: "Existing key is replaced", ext: json.RawMessage(`{"responsetimemillis":5}`), expectedExt: `{"responsetimemillis":12}`},
+	}
+	for _, test := range testCases {
+		seatBid := &pbsOrtbSeatBid{ext: test.ext}
+		if assert.NoError(t, seatBid.setExtField("responsetimemillis", 12), test.description) {
+			assert.JSONEq(t, test.expectedExt, string(seatBid.ext), test.description)
+		}
+	}
+}
+
+func TestSetExtFieldInvalidExt(t *testing.T) {
+	seatBid := &pbsOrtbSeatBid{ext: json.RawMessage(`["not", "an", "object"]`)}
+
+	assert.Error(t, seatBid.setExtField("debug", true))
+	assert.Equal(t, `["not", "an", "object"]`, string(seatBid.ext), "The ext shouldn't change if it can't be parsed.")
+}
+
+func TestGetExtField(t *testing.T) {
+	seatBid := &pbsOrtbSeatBid{ext: json.RawMessage(`{"responsetimemillis":12}`)}
+
+	var responseTime int
+	found, err := seatBid.getExtField("responsetimemillis", &responseTime)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 12, responseTime)
+
+	var debug bool
+	found, err = seatBid.getExtField("debug", &debug)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	found, err = (&pbsOrtbSeatBid{}).getExtField("debug", &debug)
+	assert.NoError(t, err)
+	assert.False(t, found, "An empty ext has no fields.")
+}
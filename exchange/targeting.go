@@ -25,6 +25,8 @@ type targetData struct {
 	// cacheHost and cachePath exist to supply cache host and path as targeting parameters
 	cacheHost string
 	cachePath string
+	// dealKey is the targeting key used for the winning bid's deal ID. If empty, openrtb_ext.HbDealIDConstantKey is used.
+	dealKey openrtb_ext.TargetingKey
 }
 
 // setTargeting writes all the targeting params into the bids.
@@ -62,7 +64,7 @@ func (targData *targetData) setTargeting(auc *auction, isApp bool, categoryMappi
 			}
 
 			if deal := topBidPerBidder.bid.DealID; len(deal) > 0 {
-				targData.addKeys(targets, openrtb_ext.HbDealIDConstantKey, deal, bidderName, isOverallWinner)
+				targData.addKeys(targets, targData.dealTargetingKey(), deal, bidderName, isOverallWinner)
 			}
 
 			if isApp {
@@ -86,6 +88,14 @@ func (targData *targetData) addKeys(keys map[string]string, key openrtb_ext.Targ
 	}
 }
 
+// dealTargetingKey returns the key which should carry a bid's deal ID, defaulting to hb_deal.
+func (targData *targetData) dealTargetingKey() openrtb_ext.TargetingKey {
+	if targData.dealKey != "" {
+		return targData.dealKey
+	}
+	return openrtb_ext.HbDealIDConstantKey
+}
+
 func makeHbSize(bid *openrtb.Bid) string {
 	if bid.W != 0 && bid.H != 0 {
 		return strconv.FormatUint(bid.W, 10) + "x" + strconv.FormatUint(bid.H, 10)
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 
 	"github.com/mxmCherry/openrtb"
 	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/config"
 	"github.com/prebid/prebid-server/openrtb_ext"
 	"github.com/stretchr/testify/assert"
 )
@@ -66,6 +68,73 @@ func TestTargetingCache(t *testing.T) {
 
 }
 
+func TestSetTargetingDealKey(t *testing.T) {
+	testCases := []struct {
+		description  string
+		dealKey      openrtb_ext.TargetingKey
+		dealID       string
+		expectedKeys map[string]string
+	}{
+		{
+			description: "Default key when no prefix is configured",
+			dealID:      "some-deal",
+			expectedKeys: map[string]string{
+				"hb_deal":          "some-deal",
+				"hb_deal_appnexus": "some-deal",
+			},
+		},
+		{
+			description: "Custom prefix",
+			dealKey:     "pb_deal",
+			dealID:      "some-deal",
+			expectedKeys: map[string]string{
+				"pb_deal":          "some-deal",
+				"pb_deal_appnexus": "some-deal",
+			},
+		},
+		{
+			description:  "No keys without a deal ID",
+			dealKey:      "pb_deal",
+			expectedKeys: map[string]string{},
+		},
+	}
+
+	for _, test := range testCases {
+		winningBid := &pbsOrtbBid{
+			bid: &openrtb.Bid{
+				ID:     "winning-bid",
+				ImpID:  "some-imp",
+				DealID: test.dealID,
+			},
+			bidType: openrtb_ext.BidTypeBanner,
+		}
+		auc := &auction{
+			winningBids: map[string]*pbsOrtbBid{
+				"some-imp": winningBid,
+			},
+			winningBidsByBidder: map[string]map[openrtb_ext.BidderName]*pbsOrtbBid{
+				"some-imp": {
+					openrtb_ext.BidderAppnexus: winningBid,
+				},
+			},
+		}
+		targData := &targetData{
+			includeWinners:    true,
+			includeBidderKeys: true,
+			dealKey:           test.dealKey,
+		}
+		targData.setTargeting(auc, false, nil)
+
+		dealTargets := make(map[string]string)
+		for key, value := range winningBid.bidTargets {
+			if strings.Contains(key, "deal") {
+				dealTargets[key] = value
+			}
+		}
+		assert.Equal(t, test.expectedKeys, dealTargets, test.description)
+	}
+}
+
 func assertKeyExists(t *testing.T, bid *openrtb.Bid, key string, expected bool) {
 	t.Helper()
 	targets := parseTargets(t, bid)
@@ -132,7 +201,7 @@ func buildAdapterMap(bids map[openrtb_ext.BidderName][]*openrtb.Bid, mockServerU
 		adapterMap[bidder] = adaptBidder(&mockTargetingBidder{
 			mockServerURL: mockServerURL,
 			bids:          bids,
-		}, client)
+		}, client, &config.Configuration{}, &metricsConf.DummyMetricsEngine{}, "test")
 	}
 	return adapterMap
 }
@@ -0,0 +1,41 @@
+package exchange
+
+import (
+	"sync"
+
+	"github.com/prebid/prebid-server/openrtb_ext"
+)
+
+// URIRewriter changes the URI of a Bidder's request just before it's sent. It's meant for routing rules which
+// are decided at runtime, like sending 10% of the traffic to a canary host or picking a regional endpoint.
+//
+// It's called concurrently, so it must be safe for concurrent use. If it returns an error, the request isn't sent,
+// and the error is reported for the Bidder.
+type URIRewriter func(uri string) (string, error)
+
+var uriRewriters struct {
+	sync.RWMutex
+	byBidder map[openrtb_ext.BidderName]URIRewriter
+}
+
+// RegisterURIRewriter sets the rewriter for the requests of the named core Bidder, replacing any earlier one.
+// Aliases use the rewriter of their core Bidder. Pass nil to stop rewriting the Bidder's requests.
+func RegisterURIRewriter(name openrtb_ext.BidderName, rewriter URIRewriter) {
+	uriRewriters.Lock()
+	defer uriRewriters.Unlock()
+	if rewriter == nil {
+		delete(uriRewriters.byBidder, name)
+		return
+	}
+	if uriRewriters.byBidder == nil {
+		uriRewriters.byBidder = make(map[openrtb_ext.BidderName]URIRewriter)
+	}
+	uriRewriters.byBidder[name] = rewriter
+}
+
+// uriRewriterFor returns nil if the Bidder's requests shouldn't be rewritten.
+func uriRewriterFor(name openrtb_ext.BidderName) URIRewriter {
+	uriRewriters.RLock()
+	defer uriRewriters.RUnlock()
+	return uriRewriters.byBidder[name]
+}
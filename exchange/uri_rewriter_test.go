@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mxmCherry/openrtb"
+	"github.com/prebid/prebid-server/adapters"
+	"github.com/prebid/prebid-server/adapters/adapterstest"
+	"github.com/prebid/prebid-server/currencies"
+	"github.com/prebid/prebid-server/errortypes"
+	"github.com/prebid/prebid-server/openrtb_ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterURIRewriter(t *testing.T) {
+	assert.Nil(t, uriRewriterFor(openrtb_ext.BidderAppnexus))
+
+	RegisterURIRewriter(openrtb_ext.BidderAppnexus, func(uri string) (string, error) { return uri, nil })
+	assert.NotNil(t, uriRewriterFor(openrtb_ext.BidderAppnexus))
+	assert.Nil(t, uriRewriterFor(openrtb_ext.BidderRubicon), "Rewriters should only apply to the Bidder they were registered for.")
+
+	RegisterURIRewriter(openrtb_ext.BidderAppnexus, nil)
+	assert.Nil(t, uriRewriterFor(openrtb_ext.BidderAppnexus))
+}
+
+func TestRequestBidRewritesURI(t *testing.T) {
+	RegisterURIRewriter(openrtb_ext.BidderAppnexus, func(uri string) (string, error) {
+		return strings.Replace(uri, "//bidder.com", "//canary.bidder.com", 1), nil
+	})
+	defer RegisterURIRewriter(openrtb_ext.BidderAppnexus, nil)
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://canary.bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Test: 1}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	if assert.NotNil(t, seatBid) && assert.Len(t, seatBid.httpCalls, 1) {
+		assert.Equal(t, "http://canary.bidder.com/bid", seatBid.httpCalls[0].Uri)
+		assert.Equal(t, "http://bidder.com/bid", seatBid.httpCalls[0].OriginalUri)
+	}
+}
+
+func TestRequestBidKeepsURI(t *testing.T) {
+	RegisterURIRewriter(openrtb_ext.BidderAppnexus, func(uri string) (string, error) { return uri, nil })
+	defer RegisterURIRewriter(openrtb_ext.BidderAppnexus, nil)
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{
+		"http://bidder.com/bid": {Body: "{}"},
+	})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+
+	seatBid, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{Test: 1}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	assert.Empty(t, errs)
+	if assert.NotNil(t, seatBid) && assert.Len(t, seatBid.httpCalls, 1) {
+		assert.Equal(t, "http://bidder.com/bid", seatBid.httpCalls[0].Uri)
+		assert.Empty(t, seatBid.httpCalls[0].OriginalUri, "URIs which weren't changed shouldn't be reported as rewritten.")
+	}
+}
+
+func TestRequestBidURIRewriterError(t *testing.T) {
+	RegisterURIRewriter(openrtb_ext.BidderAppnexus, func(uri string) (string, error) { return "", errors.New("no route") })
+	defer RegisterURIRewriter(openrtb_ext.BidderAppnexus, nil)
+
+	bidderImpl := &goodSingleBidder{
+		httpRequest: &adapters.RequestData{
+			Method: "POST",
+			Uri:    "http://bidder.com/bid",
+		},
+	}
+	bidder := newMockTransportBidder(bidderImpl, map[string]adapterstest.MockResponse{})
+	bidder.BidderName = openrtb_ext.BidderAppnexus
+
+	_, errs := bidder.requestBid(context.Background(), &openrtb.BidRequest{}, "test", 1.0, currencies.NewConstantRates(), &adapters.ExtraRequestInfo{}, bidRequestOptions{})
+
+	if assert.Len(t, errs, 1) {
+		assert.IsType(t, &errortypes.FailedToRequestBids{}, errs[0])
+		assert.Contains(t, errs[0].Error(), "no route")
+	}
+	assert.Nil(t, bidderImpl.httpResponse, "The request shouldn't be sent if its URI couldn't be rewritten.")
+}
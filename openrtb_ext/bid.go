@@ -17,6 +17,9 @@ type ExtBidPrebid struct {
 	Targeting map[string]string  `json:"targeting,omitempty"`
 	Type      BidType            `json:"type"`
 	Video     *ExtBidPrebidVideo `json:"video,omitempty"`
+	// HttpCall is the index in response.ext.debug.httpcalls.{bidder} of the call which returned this bid.
+	// It's only set for test requests.
+	HttpCall *int `json:"httpcall,omitempty"`
 }
 
 // ExtBidPrebidCache defines the contract for  bidresponse.seatbid.bid[i].ext.prebid.cache
@@ -18,6 +18,22 @@ type ExtRequestPrebid struct {
 	StoredRequest        *ExtStoredRequest      `json:"storedrequest,omitempty"`
 	Targeting            *ExtRequestTargeting   `json:"targeting,omitempty"`
 	SupportDeals         bool                   `json:"supportdeals,omitempty"`
+	// AllowedImps maps bidder names to the only Imp IDs which that bidder's bids will be accepted for.
+	// Bidders which aren't in the map may bid on any Imp.
+	AllowedImps map[string][]string `json:"allowedimps,omitempty"`
+	// ADomainFilter tightens the host's adomain filter for this request.
+	ADomainFilter *ExtRequestPrebidADomainFilter `json:"adomainfilter,omitempty"`
+}
+
+// ExtRequestPrebidADomainFilter defines the contract for bidrequest.ext.prebid.adomainfilter
+type ExtRequestPrebidADomainFilter struct {
+	// Allowed domains are checked in addition to the host's allow list, so bids must pass both.
+	Allowed []string `json:"allowed,omitempty"`
+	// Denied domains are added to the host's deny list.
+	Denied []string `json:"denied,omitempty"`
+	// DenyEmpty and WarnOnly override the host's settings, if present.
+	DenyEmpty *bool `json:"denyempty,omitempty"`
+	WarnOnly  *bool `json:"warnonly,omitempty"`
 }
 
 // ExtRequestPrebidCache defines the contract for bidrequest.ext.prebid.cache
@@ -17,6 +17,9 @@ type ExtBidResponse struct {
 	RequestTimeoutMillis int64 `json:"tmaxrequest,omitempty"`
 	// ResponseUserSync defines the contract for bidresponse.ext.usersync
 	Usersync map[BidderName]*ExtResponseSyncData `json:"usersync,omitempty"`
+	// Partial defines the contract for bidresponse.ext.partial. It only lists the bidders for which some
+	// requests failed while others succeeded, so their bids may be incomplete.
+	Partial map[BidderName]bool `json:"partial,omitempty"`
 }
 
 // ExtResponseDebug defines the contract for bidresponse.ext.debug
@@ -25,6 +28,15 @@ type ExtResponseDebug struct {
 	HttpCalls map[BidderName][]*ExtHttpCall `json:"httpcalls,omitempty"`
 	// Request after resolution of stored requests and debug overrides
 	ResolvedRequest *openrtb.BidRequest `json:"resolvedrequest,omitempty"`
+	// CurrencyConversions defines the contract for bidresponse.ext.debug.currencyconversions
+	CurrencyConversions map[BidderName][]ExtCurrencyConversion `json:"currencyconversions,omitempty"`
+}
+
+// ExtCurrencyConversion describes a conversion which was applied to a bidder's bid prices.
+type ExtCurrencyConversion struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Rate float64 `json:"rate"`
 }
 
 // ExtResponseSyncData defines the contract for bidresponse.ext.usersync.{bidder}
@@ -42,7 +54,9 @@ type ExtUserSync struct {
 
 // ExtBidderError defines an error object to be returned, consiting of a machine readable error code, and a human readable error message string.
 type ExtBidderError struct {
-	Code    int    `json:"code"`
+	Code int `json:"code"`
+	// Subcode further describes some errors, such as which kind of bad response a bidder's server sent.
+	Subcode int    `json:"subcode,omitempty"`
 	Message string `json:"message"`
 }
 
@@ -52,6 +66,23 @@ type ExtHttpCall struct {
 	RequestBody  string `json:"requestbody"`
 	ResponseBody string `json:"responsebody"`
 	Status       int    `json:"status"`
+	// PartialResponse is true if the call failed while the response was being read,
+	// in which case ResponseBody only holds the bytes which arrived before the failure.
+	PartialResponse bool `json:"partialresponse,omitempty"`
+	// Connection describes how the connection to the bidder was set up. It's omitted if no connection was made.
+	Connection *ExtHttpCallConnection `json:"connection,omitempty"`
+	// Cached is true if the response came from Prebid Server's cache, and the bidder wasn't called.
+	Cached bool `json:"cached,omitempty"`
+	// OriginalUri is the URI which the bidder asked for, if Prebid Server rewrote it. Uri is where the call went.
+	OriginalUri string `json:"originaluri,omitempty"`
+}
+
+// ExtHttpCallConnection helps to diagnose connection churn between Prebid Server and a bidder.
+type ExtHttpCallConnection struct {
+	// Reused is true if the call was made on an idle connection left over from an earlier call.
+	Reused bool `json:"reused"`
+	// TLSHandshake is true if a TLS handshake was started for the call.
+	TLSHandshake bool `json:"tlshandshake"`
 }
 
 // CookieStatus describes the allowed values for bidresponse.ext.usersync.{bidder}.status
@@ -125,6 +125,48 @@ func (me *MultiMetricsEngine) RecordAdapterBidReceived(labels pbsmetrics.Adapter
 	}
 }
 
+// RecordAdapterBidsDropped across all engines
+func (me *MultiMetricsEngine) RecordAdapterBidsDropped(adapter openrtb_ext.BidderName, reason pbsmetrics.BidDropReason, count int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterBidsDropped(adapter, reason, count)
+	}
+}
+
+// RecordAdapterCurrencySelection across all engines
+func (me *MultiMetricsEngine) RecordAdapterCurrencySelection(adapter openrtb_ext.BidderName, selection pbsmetrics.CurrencySelection) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCurrencySelection(adapter, selection)
+	}
+}
+
+// RecordAdapterCircuitBreakerTransition across all engines
+func (me *MultiMetricsEngine) RecordAdapterCircuitBreakerTransition(adapter openrtb_ext.BidderName, state pbsmetrics.CircuitBreakerState) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterCircuitBreakerTransition(adapter, state)
+	}
+}
+
+// RecordAdapterWinNotification across all engines
+func (me *MultiMetricsEngine) RecordAdapterWinNotification(adapter openrtb_ext.BidderName, delivered bool) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterWinNotification(adapter, delivered)
+	}
+}
+
+// RecordAdapterGeneratedBidIDs across all engines
+func (me *MultiMetricsEngine) RecordAdapterGeneratedBidIDs(adapter openrtb_ext.BidderName, count int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterGeneratedBidIDs(adapter, count)
+	}
+}
+
+// RecordAdapterMarkupSize across all engines
+func (me *MultiMetricsEngine) RecordAdapterMarkupSize(adapter openrtb_ext.BidderName, bytes int) {
+	for _, thisME := range *me {
+		thisME.RecordAdapterMarkupSize(adapter, bytes)
+	}
+}
+
 // RecordAdapterPrice across all engines
 func (me *MultiMetricsEngine) RecordAdapterPrice(labels pbsmetrics.AdapterLabels, cpm float64) {
 	for _, thisME := range *me {
@@ -227,6 +269,30 @@ func (me *DummyMetricsEngine) RecordAdapterRequest(labels pbsmetrics.AdapterLabe
 func (me *DummyMetricsEngine) RecordAdapterBidReceived(labels pbsmetrics.AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool) {
 }
 
+// RecordAdapterBidsDropped as a noop
+func (me *DummyMetricsEngine) RecordAdapterBidsDropped(adapter openrtb_ext.BidderName, reason pbsmetrics.BidDropReason, count int) {
+}
+
+// RecordAdapterCurrencySelection as a noop
+func (me *DummyMetricsEngine) RecordAdapterCurrencySelection(adapter openrtb_ext.BidderName, selection pbsmetrics.CurrencySelection) {
+}
+
+// RecordAdapterCircuitBreakerTransition as a noop
+func (me *DummyMetricsEngine) RecordAdapterCircuitBreakerTransition(adapter openrtb_ext.BidderName, state pbsmetrics.CircuitBreakerState) {
+}
+
+// RecordAdapterWinNotification as a noop
+func (me *DummyMetricsEngine) RecordAdapterWinNotification(adapter openrtb_ext.BidderName, delivered bool) {
+}
+
+// RecordAdapterGeneratedBidIDs as a noop
+func (me *DummyMetricsEngine) RecordAdapterGeneratedBidIDs(adapter openrtb_ext.BidderName, count int) {
+}
+
+// RecordAdapterMarkupSize as a noop
+func (me *DummyMetricsEngine) RecordAdapterMarkupSize(adapter openrtb_ext.BidderName, bytes int) {
+}
+
 // RecordAdapterPrice as a noop
 func (me *DummyMetricsEngine) RecordAdapterPrice(labels pbsmetrics.AdapterLabels, cpm float64) {
 }
@@ -61,15 +61,22 @@ type Metrics struct {
 
 // AdapterMetrics houses the metrics for a particular adapter
 type AdapterMetrics struct {
-	NoCookieMeter     metrics.Meter
-	ErrorMeters       map[AdapterError]metrics.Meter
-	NoBidMeter        metrics.Meter
-	GotBidsMeter      metrics.Meter
-	RequestTimer      metrics.Timer
-	PriceHistogram    metrics.Histogram
-	BidsReceivedMeter metrics.Meter
-	PanicMeter        metrics.Meter
-	MarkupMetrics     map[openrtb_ext.BidType]*MarkupDeliveryMetrics
+	NoCookieMeter        metrics.Meter
+	ErrorMeters          map[AdapterError]metrics.Meter
+	NoBidMeter           metrics.Meter
+	GotBidsMeter         metrics.Meter
+	RequestTimer         metrics.Timer
+	PriceHistogram       metrics.Histogram
+	BidsReceivedMeter    metrics.Meter
+	PanicMeter           metrics.Meter
+	MarkupMetrics        map[openrtb_ext.BidType]*MarkupDeliveryMetrics
+	DroppedBidsMeters    map[BidDropReason]metrics.Meter
+	CurrencyMeters       map[CurrencySelection]metrics.Meter
+	CircuitBreakerMeters map[CircuitBreakerState]metrics.Meter
+	WinNoticeOkMeter     metrics.Meter
+	WinNoticeErrMeter    metrics.Meter
+	GeneratedBidIDsMeter metrics.Meter
+	MarkupSizeHistogram  metrics.Histogram
 }
 
 type MarkupDeliveryMetrics struct {
@@ -216,19 +223,35 @@ func NewMetrics(registry metrics.Registry, exchanges []openrtb_ext.BidderName, d
 func makeBlankAdapterMetrics() *AdapterMetrics {
 	blankMeter := &metrics.NilMeter{}
 	newAdapter := &AdapterMetrics{
-		NoCookieMeter:     blankMeter,
-		ErrorMeters:       make(map[AdapterError]metrics.Meter),
-		NoBidMeter:        blankMeter,
-		GotBidsMeter:      blankMeter,
-		RequestTimer:      &metrics.NilTimer{},
-		PriceHistogram:    &metrics.NilHistogram{},
-		BidsReceivedMeter: blankMeter,
-		PanicMeter:        blankMeter,
-		MarkupMetrics:     makeBlankBidMarkupMetrics(),
+		NoCookieMeter:        blankMeter,
+		ErrorMeters:          make(map[AdapterError]metrics.Meter),
+		NoBidMeter:           blankMeter,
+		GotBidsMeter:         blankMeter,
+		RequestTimer:         &metrics.NilTimer{},
+		PriceHistogram:       &metrics.NilHistogram{},
+		BidsReceivedMeter:    blankMeter,
+		PanicMeter:           blankMeter,
+		MarkupMetrics:        makeBlankBidMarkupMetrics(),
+		DroppedBidsMeters:    make(map[BidDropReason]metrics.Meter),
+		CurrencyMeters:       make(map[CurrencySelection]metrics.Meter),
+		CircuitBreakerMeters: make(map[CircuitBreakerState]metrics.Meter),
+		WinNoticeOkMeter:     blankMeter,
+		WinNoticeErrMeter:    blankMeter,
+		GeneratedBidIDsMeter: blankMeter,
+		MarkupSizeHistogram:  &metrics.NilHistogram{},
 	}
 	for _, err := range AdapterErrors() {
 		newAdapter.ErrorMeters[err] = blankMeter
 	}
+	for _, reason := range BidDropReasons() {
+		newAdapter.DroppedBidsMeters[reason] = blankMeter
+	}
+	for _, selection := range CurrencySelections() {
+		newAdapter.CurrencyMeters[selection] = blankMeter
+	}
+	for _, state := range CircuitBreakerStates() {
+		newAdapter.CircuitBreakerMeters[state] = blankMeter
+	}
 	return newAdapter
 }
 
@@ -267,6 +290,21 @@ func registerAdapterMetrics(registry metrics.Registry, adapterOrAccount string,
 		am.BidsReceivedMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.bids_received", adapterOrAccount, exchange), registry)
 	}
 	am.PanicMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.requests.panic", adapterOrAccount, exchange), registry)
+	if adapterOrAccount == "adapter" {
+		for reason := range am.DroppedBidsMeters {
+			am.DroppedBidsMeters[reason] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.bids_dropped.%s", adapterOrAccount, exchange, reason), registry)
+		}
+		for selection := range am.CurrencyMeters {
+			am.CurrencyMeters[selection] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.currency_selection.%s", adapterOrAccount, exchange, selection), registry)
+		}
+		for state := range am.CircuitBreakerMeters {
+			am.CircuitBreakerMeters[state] = metrics.GetOrRegisterMeter(fmt.Sprintf("%s.%s.circuit_breaker.%s", adapterOrAccount, exchange, state), registry)
+		}
+		am.WinNoticeOkMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.win_notifications.ok", adapterOrAccount, exchange), registry)
+		am.WinNoticeErrMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.win_notifications.err", adapterOrAccount, exchange), registry)
+		am.GeneratedBidIDsMeter = metrics.GetOrRegisterMeter(fmt.Sprintf("%[1]s.%[2]s.generated_bid_ids", adapterOrAccount, exchange), registry)
+		am.MarkupSizeHistogram = metrics.GetOrRegisterHistogram(fmt.Sprintf("%[1]s.%[2]s.markup_bytes", adapterOrAccount, exchange), registry, metrics.NewExpDecaySample(1028, 0.015))
+	}
 }
 
 func makeDeliveryMetrics(registry metrics.Registry, prefix string, bidType openrtb_ext.BidType) *MarkupDeliveryMetrics {
@@ -398,6 +436,76 @@ func (me *Metrics) RecordAdapterPanic(labels AdapterLabels) {
 	am.PanicMeter.Mark(1)
 }
 
+// RecordAdapterBidsDropped implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterBidsDropped(adapter openrtb_ext.BidderName, reason BidDropReason, count int) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	if meter, ok := am.DroppedBidsMeters[reason]; ok {
+		meter.Mark(int64(count))
+	}
+}
+
+// RecordAdapterCurrencySelection implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterCurrencySelection(adapter openrtb_ext.BidderName, selection CurrencySelection) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	if meter, ok := am.CurrencyMeters[selection]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordAdapterCircuitBreakerTransition implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterCircuitBreakerTransition(adapter openrtb_ext.BidderName, state CircuitBreakerState) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	if meter, ok := am.CircuitBreakerMeters[state]; ok {
+		meter.Mark(1)
+	}
+}
+
+// RecordAdapterWinNotification implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterWinNotification(adapter openrtb_ext.BidderName, delivered bool) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	if delivered {
+		am.WinNoticeOkMeter.Mark(1)
+	} else {
+		am.WinNoticeErrMeter.Mark(1)
+	}
+}
+
+// RecordAdapterGeneratedBidIDs implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterGeneratedBidIDs(adapter openrtb_ext.BidderName, count int) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	am.GeneratedBidIDsMeter.Mark(int64(count))
+}
+
+// RecordAdapterMarkupSize implements a part of the MetricsEngine interface
+func (me *Metrics) RecordAdapterMarkupSize(adapter openrtb_ext.BidderName, bytes int) {
+	am, ok := me.AdapterMetrics[adapter]
+	if !ok {
+		glog.Errorf("Trying to run adapter metrics on %s: adapter metrics not found", string(adapter))
+		return
+	}
+	am.MarkupSizeHistogram.Update(int64(bytes))
+}
+
 // RecordAdapterRequest implements a part of the MetricsEngine interface
 func (me *Metrics) RecordAdapterRequest(labels AdapterLabels) {
 	am, ok := me.AdapterMetrics[labels.Adapter]
@@ -72,6 +72,59 @@ func TestRecordBidType(t *testing.T) {
 	VerifyMetrics(t, "Appnexus Video Nurl Bids", m.AdapterMetrics[openrtb_ext.BidderAppnexus].MarkupMetrics[openrtb_ext.BidTypeVideo].NurlMeter.Count(), 1)
 }
 
+func TestRecordAdapterBidsDropped(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterBidsDropped(openrtb_ext.BidderAppnexus, BidDropReasonBidLimit, 3)
+	m.RecordAdapterBidsDropped(openrtb_ext.BidderAppnexus, BidDropReasonBidLimit, 2)
+	m.RecordAdapterBidsDropped(openrtb_ext.BidderRubicon, BidDropReasonBidLimit, 1)
+
+	droppedBids := m.AdapterMetrics[openrtb_ext.BidderAppnexus].DroppedBidsMeters
+	VerifyMetrics(t, "Appnexus Bids Dropped By Bid Limit", droppedBids[BidDropReasonBidLimit].Count(), 5)
+	VerifyMetrics(t, "Appnexus Bids Dropped By Currency Conversion", droppedBids[BidDropReasonCurrencyConversion].Count(), 0)
+}
+
+func TestRecordAdapterCurrencySelection(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterCurrencySelection(openrtb_ext.BidderAppnexus, CurrencySelectionFirst)
+	m.RecordAdapterCurrencySelection(openrtb_ext.BidderAppnexus, CurrencySelectionFallback)
+	m.RecordAdapterCurrencySelection(openrtb_ext.BidderAppnexus, CurrencySelectionFirst)
+
+	currencyMeters := m.AdapterMetrics[openrtb_ext.BidderAppnexus].CurrencyMeters
+	VerifyMetrics(t, "Appnexus First Currency", currencyMeters[CurrencySelectionFirst].Count(), 2)
+	VerifyMetrics(t, "Appnexus Other Currency", currencyMeters[CurrencySelectionOther].Count(), 0)
+	VerifyMetrics(t, "Appnexus Fallback Currency", currencyMeters[CurrencySelectionFallback].Count(), 1)
+}
+
+func TestRecordAdapterCircuitBreakerTransition(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterCircuitBreakerTransition(openrtb_ext.BidderAppnexus, CircuitBreakerOpen)
+	m.RecordAdapterCircuitBreakerTransition(openrtb_ext.BidderAppnexus, CircuitBreakerHalfOpen)
+	m.RecordAdapterCircuitBreakerTransition(openrtb_ext.BidderAppnexus, CircuitBreakerOpen)
+
+	breakerMeters := m.AdapterMetrics[openrtb_ext.BidderAppnexus].CircuitBreakerMeters
+	VerifyMetrics(t, "Appnexus Circuit Breaker Opened", breakerMeters[CircuitBreakerOpen].Count(), 2)
+	VerifyMetrics(t, "Appnexus Circuit Breaker Half-Opened", breakerMeters[CircuitBreakerHalfOpen].Count(), 1)
+	VerifyMetrics(t, "Appnexus Circuit Breaker Closed", breakerMeters[CircuitBreakerClosed].Count(), 0)
+}
+
+func TestRecordAdapterWinNotification(t *testing.T) {
+	registry := metrics.NewRegistry()
+	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
+
+	m.RecordAdapterWinNotification(openrtb_ext.BidderAppnexus, true)
+	m.RecordAdapterWinNotification(openrtb_ext.BidderAppnexus, true)
+	m.RecordAdapterWinNotification(openrtb_ext.BidderAppnexus, false)
+
+	VerifyMetrics(t, "Appnexus Win Notifications Delivered", m.AdapterMetrics[openrtb_ext.BidderAppnexus].WinNoticeOkMeter.Count(), 2)
+	VerifyMetrics(t, "Appnexus Win Notifications Failed", m.AdapterMetrics[openrtb_ext.BidderAppnexus].WinNoticeErrMeter.Count(), 1)
+}
+
 func TestRecordGDPRRejection(t *testing.T) {
 	registry := metrics.NewRegistry()
 	m := NewMetrics(registry, []openrtb_ext.BidderName{openrtb_ext.BidderAppnexus}, config.DisabledMetrics{})
@@ -104,6 +157,26 @@ func ensureContainsAdapterMetrics(t *testing.T, registry metrics.Registry, name
 	ensureContains(t, registry, name+".request_time", adapterMetrics.RequestTimer)
 	ensureContains(t, registry, name+".prices", adapterMetrics.PriceHistogram)
 	ensureContainsBidTypeMetrics(t, registry, name, adapterMetrics.MarkupMetrics)
+
+	ensureContains(t, registry, name+".bids_dropped.currency_conversion", adapterMetrics.DroppedBidsMeters[BidDropReasonCurrencyConversion])
+	ensureContains(t, registry, name+".bids_dropped.bid_limit", adapterMetrics.DroppedBidsMeters[BidDropReasonBidLimit])
+	ensureContains(t, registry, name+".bids_dropped.imp_not_allowed", adapterMetrics.DroppedBidsMeters[BidDropReasonImpNotAllowed])
+	ensureContains(t, registry, name+".bids_dropped.adomain_denied", adapterMetrics.DroppedBidsMeters[BidDropReasonADomainDenied])
+	ensureContains(t, registry, name+".bids_dropped.adomain_not_allowed", adapterMetrics.DroppedBidsMeters[BidDropReasonADomainNotAllowed])
+	ensureContains(t, registry, name+".bids_dropped.adomain_missing", adapterMetrics.DroppedBidsMeters[BidDropReasonADomainMissing])
+	ensureContains(t, registry, name+".circuit_breaker.open", adapterMetrics.CircuitBreakerMeters[CircuitBreakerOpen])
+	ensureContains(t, registry, name+".circuit_breaker.half_open", adapterMetrics.CircuitBreakerMeters[CircuitBreakerHalfOpen])
+	ensureContains(t, registry, name+".circuit_breaker.closed", adapterMetrics.CircuitBreakerMeters[CircuitBreakerClosed])
+	ensureContains(t, registry, name+".win_notifications.ok", adapterMetrics.WinNoticeOkMeter)
+	ensureContains(t, registry, name+".win_notifications.err", adapterMetrics.WinNoticeErrMeter)
+	ensureContains(t, registry, name+".generated_bid_ids", adapterMetrics.GeneratedBidIDsMeter)
+	ensureContains(t, registry, name+".markup_bytes", adapterMetrics.MarkupSizeHistogram)
+	ensureContains(t, registry, name+".bids_dropped.markup_too_large", adapterMetrics.DroppedBidsMeters[BidDropReasonMarkupTooLarge])
+	ensureContains(t, registry, name+".bids_dropped.rejected", adapterMetrics.DroppedBidsMeters[BidDropReasonRejected])
+	ensureContains(t, registry, name+".bids_dropped.invalid_price", adapterMetrics.DroppedBidsMeters[BidDropReasonInvalidPrice])
+	ensureContains(t, registry, name+".currency_selection.first", adapterMetrics.CurrencyMeters[CurrencySelectionFirst])
+	ensureContains(t, registry, name+".currency_selection.other", adapterMetrics.CurrencyMeters[CurrencySelectionOther])
+	ensureContains(t, registry, name+".currency_selection.fallback", adapterMetrics.CurrencyMeters[CurrencySelectionFallback])
 }
 
 func TestRecordBidTypeDisabledConfig(t *testing.T) {
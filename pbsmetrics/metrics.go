@@ -70,6 +70,15 @@ type AdapterError string
 // CacheResult : Cache hit/miss
 type CacheResult string
 
+// BidDropReason : Why the exchange discarded a bid which the adapter returned
+type BidDropReason string
+
+// CurrencySelection : How the currency of an adapter's bids was picked
+type CurrencySelection string
+
+// CircuitBreakerState : Whether calls to the adapter's endpoint are being let through
+type CircuitBreakerState string
+
 // PublisherUnknown : Default value for Labels.PubID
 const PublisherUnknown = "unknown"
 
@@ -205,6 +214,69 @@ func AdapterErrors() []AdapterError {
 	}
 }
 
+// Reasons for dropping an adapter's bids
+const (
+	BidDropReasonCurrencyConversion BidDropReason = "currency_conversion"
+	BidDropReasonBidLimit           BidDropReason = "bid_limit"
+	BidDropReasonImpNotAllowed      BidDropReason = "imp_not_allowed"
+	BidDropReasonADomainDenied      BidDropReason = "adomain_denied"
+	BidDropReasonADomainNotAllowed  BidDropReason = "adomain_not_allowed"
+	BidDropReasonADomainMissing     BidDropReason = "adomain_missing"
+	BidDropReasonMarkupTooLarge     BidDropReason = "markup_too_large"
+	BidDropReasonRejected           BidDropReason = "rejected"
+	BidDropReasonInvalidPrice       BidDropReason = "invalid_price"
+)
+
+// BidDropReasons returns all possible reasons for dropping bids
+func BidDropReasons() []BidDropReason {
+	return []BidDropReason{
+		BidDropReasonCurrencyConversion,
+		BidDropReasonBidLimit,
+		BidDropReasonImpNotAllowed,
+		BidDropReasonADomainDenied,
+		BidDropReasonADomainNotAllowed,
+		BidDropReasonADomainMissing,
+		BidDropReasonMarkupTooLarge,
+		BidDropReasonRejected,
+		BidDropReasonInvalidPrice,
+	}
+}
+
+// Ways of picking the currency of an adapter's bids
+const (
+	// The first currency in request.cur
+	CurrencySelectionFirst CurrencySelection = "first"
+	// A later currency in request.cur, because the bids couldn't be converted to the ones before it
+	CurrencySelectionOther CurrencySelection = "other"
+	// The configured fallback currency, because the bids couldn't be converted to any currency in request.cur
+	CurrencySelectionFallback CurrencySelection = "fallback"
+)
+
+// CurrencySelections returns all possible ways of picking the currency of an adapter's bids
+func CurrencySelections() []CurrencySelection {
+	return []CurrencySelection{
+		CurrencySelectionFirst,
+		CurrencySelectionOther,
+		CurrencySelectionFallback,
+	}
+}
+
+// Adapter circuit breaker states
+const (
+	CircuitBreakerOpen     CircuitBreakerState = "open"
+	CircuitBreakerHalfOpen CircuitBreakerState = "half_open"
+	CircuitBreakerClosed   CircuitBreakerState = "closed"
+)
+
+// CircuitBreakerStates returns all possible circuit breaker states
+func CircuitBreakerStates() []CircuitBreakerState {
+	return []CircuitBreakerState{
+		CircuitBreakerOpen,
+		CircuitBreakerHalfOpen,
+		CircuitBreakerClosed,
+	}
+}
+
 const (
 	// CacheHit represents a cache hit i.e the key was found in cache
 	CacheHit CacheResult = "hit"
@@ -266,6 +338,18 @@ type MetricsEngine interface {
 	// This records whether or not a bid of a particular type uses `adm` or `nurl`.
 	// Since the legacy endpoints don't have a bid type, it can only count bids from OpenRTB and AMP.
 	RecordAdapterBidReceived(labels AdapterLabels, bidType openrtb_ext.BidType, hasAdm bool)
+	// This records bids which the adapter returned, but which the exchange discarded before the auction.
+	RecordAdapterBidsDropped(adapter openrtb_ext.BidderName, reason BidDropReason, count int)
+	// This records how the currency was picked for each of the adapter's responses which could be converted.
+	RecordAdapterCurrencySelection(adapter openrtb_ext.BidderName, selection CurrencySelection)
+	// This records each time an adapter's circuit breaker changes to the given state.
+	RecordAdapterCircuitBreakerTransition(adapter openrtb_ext.BidderName, state CircuitBreakerState)
+	// This records whether the server-side win notifications sent to adapters were delivered.
+	RecordAdapterWinNotification(adapter openrtb_ext.BidderName, delivered bool)
+	// This records how many bids were given IDs by Prebid Server, because the adapter left them blank.
+	RecordAdapterGeneratedBidIDs(adapter openrtb_ext.BidderName, count int)
+	// This records the size of each bid's markup in bytes. Bids without markup aren't recorded.
+	RecordAdapterMarkupSize(adapter openrtb_ext.BidderName, bytes int)
 	RecordAdapterPrice(labels AdapterLabels, cpm float64)
 	RecordAdapterTime(labels AdapterLabels, length time.Duration)
 	RecordCookieSync()
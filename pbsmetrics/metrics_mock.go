@@ -57,6 +57,36 @@ func (me *MetricsEngineMock) RecordAdapterBidReceived(labels AdapterLabels, bidT
 	me.Called(labels, bidType, hasAdm)
 }
 
+// RecordAdapterBidsDropped mock
+func (me *MetricsEngineMock) RecordAdapterBidsDropped(adapter openrtb_ext.BidderName, reason BidDropReason, count int) {
+	me.Called(adapter, reason, count)
+}
+
+// RecordAdapterCurrencySelection mock
+func (me *MetricsEngineMock) RecordAdapterCurrencySelection(adapter openrtb_ext.BidderName, selection CurrencySelection) {
+	me.Called(adapter, selection)
+}
+
+// RecordAdapterCircuitBreakerTransition mock
+func (me *MetricsEngineMock) RecordAdapterCircuitBreakerTransition(adapter openrtb_ext.BidderName, state CircuitBreakerState) {
+	me.Called(adapter, state)
+}
+
+// RecordAdapterWinNotification mock
+func (me *MetricsEngineMock) RecordAdapterWinNotification(adapter openrtb_ext.BidderName, delivered bool) {
+	me.Called(adapter, delivered)
+}
+
+// RecordAdapterGeneratedBidIDs mock
+func (me *MetricsEngineMock) RecordAdapterGeneratedBidIDs(adapter openrtb_ext.BidderName, count int) {
+	me.Called(adapter, count)
+}
+
+// RecordAdapterMarkupSize mock
+func (me *MetricsEngineMock) RecordAdapterMarkupSize(adapter openrtb_ext.BidderName, bytes int) {
+	me.Called(adapter, bytes)
+}
+
 // RecordAdapterPrice mock
 func (me *MetricsEngineMock) RecordAdapterPrice(labels AdapterLabels, cpm float64) {
 	me.Called(labels, cpm)
@@ -10,11 +10,15 @@ func preloadLabelValues(m *Metrics) {
 		actionValues          = actionsAsString()
 		adapterValues         = adaptersAsString()
 		adapterErrorValues    = adapterErrorsAsString()
-		bidTypeValues         = []string{markupDeliveryAdm, markupDeliveryNurl}
+		bidTypeValues         = bidTypesAsString()
 		boolValues            = boolValuesAsString()
+		breakerStateValues    = circuitBreakerStatesAsString()
 		cacheResultValues     = cacheResultsAsString()
 		cookieValues          = cookieTypesAsString()
+		currencyValues        = currencySelectionsAsString()
 		connectionErrorValues = []string{connectionAcceptError, connectionCloseError}
+		dropReasonValues      = bidDropReasonsAsString()
+		markupDeliveryValues  = []string{markupDeliveryAdm, markupDeliveryNurl}
 		requestStatusValues   = requestStatusesAsString()
 		requestTypeValues     = requestTypesAsString()
 	)
@@ -57,7 +61,23 @@ func preloadLabelValues(m *Metrics) {
 
 	preloadLabelValuesForCounter(m.adapterBids, map[string][]string{
 		adapterLabel:        adapterValues,
-		markupDeliveryLabel: bidTypeValues,
+		bidTypeLabel:        bidTypeValues,
+		markupDeliveryLabel: markupDeliveryValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterBidsDropped, map[string][]string{
+		adapterLabel:    adapterValues,
+		dropReasonLabel: dropReasonValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterCurrencies, map[string][]string{
+		adapterLabel:  adapterValues,
+		currencyLabel: currencyValues,
+	})
+
+	preloadLabelValuesForCounter(m.adapterBreaker, map[string][]string{
+		adapterLabel:      adapterValues,
+		breakerStateLabel: breakerStateValues,
 	})
 
 	preloadLabelValuesForCounter(m.adapterCookieSync, map[string][]string{
@@ -70,6 +90,10 @@ func preloadLabelValues(m *Metrics) {
 		adapterErrorLabel: adapterErrorValues,
 	})
 
+	preloadLabelValuesForCounter(m.adapterGeneratedIDs, map[string][]string{
+		adapterLabel: adapterValues,
+	})
+
 	preloadLabelValuesForCounter(m.adapterPanics, map[string][]string{
 		adapterLabel: adapterValues,
 	})
@@ -93,6 +117,11 @@ func preloadLabelValues(m *Metrics) {
 		actionLabel:  actionValues,
 	})
 
+	preloadLabelValuesForCounter(m.adapterWinNotices, map[string][]string{
+		adapterLabel: adapterValues,
+		successLabel: boolValues,
+	})
+
 	//to minimize memory usage, queuedTimeout metric is now supported for video endpoint only
 	//boolean value represents 2 general request statuses: accepted and rejected
 	preloadLabelValuesForHistogram(m.requestsQueueTimer, map[string][]string{
@@ -31,13 +31,19 @@ type Metrics struct {
 
 	// Adapter Metrics
 	adapterBids          *prometheus.CounterVec
+	adapterBidsDropped   *prometheus.CounterVec
+	adapterBreaker       *prometheus.CounterVec
 	adapterCookieSync    *prometheus.CounterVec
+	adapterCurrencies    *prometheus.CounterVec
 	adapterErrors        *prometheus.CounterVec
+	adapterGeneratedIDs  *prometheus.CounterVec
+	adapterMarkupSize    *prometheus.HistogramVec
 	adapterPanics        *prometheus.CounterVec
 	adapterPrices        *prometheus.HistogramVec
 	adapterRequests      *prometheus.CounterVec
 	adapterRequestsTimer *prometheus.HistogramVec
 	adapterUserSync      *prometheus.CounterVec
+	adapterWinNotices    *prometheus.CounterVec
 
 	// Account Metrics
 	accountRequests *prometheus.CounterVec
@@ -49,9 +55,12 @@ const (
 	adapterErrorLabel    = "adapter_error"
 	adapterLabel         = "adapter"
 	bidTypeLabel         = "bid_type"
+	breakerStateLabel    = "breaker_state"
 	cacheResultLabel     = "cache_result"
 	connectionErrorLabel = "connection_error"
 	cookieLabel          = "cookie"
+	currencyLabel        = "currency_selection"
+	dropReasonLabel      = "drop_reason"
 	hasBidsLabel         = "has_bids"
 	isAudioLabel         = "audio"
 	isBannerLabel        = "banner"
@@ -85,6 +94,7 @@ func NewMetrics(cfg config.PrometheusMetrics) *Metrics {
 	cacheWriteTimeBuckets := []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1, 0.2, 0.3, 0.4, 0.5, 1}
 	priceBuckets := []float64{250, 500, 750, 1000, 1500, 2000, 2500, 3000, 3500, 4000}
 	queuedRequestTimeBuckets := []float64{0, 1, 5, 30, 60, 120, 180, 240, 300}
+	markupSizeBuckets := []float64{1024, 4096, 16384, 65536, 262144, 1048576}
 
 	metrics := Metrics{}
 	metrics.Registry = prometheus.NewRegistry()
@@ -149,8 +159,23 @@ func NewMetrics(cfg config.PrometheusMetrics) *Metrics {
 
 	metrics.adapterBids = newCounter(cfg, metrics.Registry,
 		"adapter_bids",
-		"Count of bids labeled by adapter and markup delivery type (adm or nurl).",
-		[]string{adapterLabel, markupDeliveryLabel})
+		"Count of bids labeled by adapter, bid type, and markup delivery type (adm or nurl).",
+		[]string{adapterLabel, bidTypeLabel, markupDeliveryLabel})
+
+	metrics.adapterBidsDropped = newCounter(cfg, metrics.Registry,
+		"adapter_bids_dropped",
+		"Count of bids discarded before the auction labeled by adapter and drop reason.",
+		[]string{adapterLabel, dropReasonLabel})
+
+	metrics.adapterBreaker = newCounter(cfg, metrics.Registry,
+		"adapter_circuit_breaker_transitions",
+		"Count of circuit breaker state changes labeled by adapter and the new state.",
+		[]string{adapterLabel, breakerStateLabel})
+
+	metrics.adapterCurrencies = newCounter(cfg, metrics.Registry,
+		"adapter_currency_selections",
+		"Count of responses whose bids could be converted labeled by adapter and how their currency was picked from request.cur.",
+		[]string{adapterLabel, currencyLabel})
 
 	metrics.adapterCookieSync = newCounter(cfg, metrics.Registry,
 		"adapter_cookie_sync",
@@ -162,6 +187,17 @@ func NewMetrics(cfg config.PrometheusMetrics) *Metrics {
 		"Count of errors labeled by adapter and error type.",
 		[]string{adapterLabel, adapterErrorLabel})
 
+	metrics.adapterGeneratedIDs = newCounter(cfg, metrics.Registry,
+		"adapter_generated_bid_ids",
+		"Count of bids which were given an ID by Prebid Server because the adapter left it blank, labeled by adapter.",
+		[]string{adapterLabel})
+
+	metrics.adapterMarkupSize = newHistogram(cfg, metrics.Registry,
+		"adapter_markup_bytes",
+		"Size in bytes of the markup of each bid labeled by adapter.",
+		[]string{adapterLabel},
+		markupSizeBuckets)
+
 	metrics.adapterPanics = newCounter(cfg, metrics.Registry,
 		"adapter_panics",
 		"Count of panics labeled by adapter.",
@@ -189,6 +225,11 @@ func NewMetrics(cfg config.PrometheusMetrics) *Metrics {
 		"Count of user ID sync requests received labeled by adapter and action.",
 		[]string{adapterLabel, actionLabel})
 
+	metrics.adapterWinNotices = newCounter(cfg, metrics.Registry,
+		"adapter_win_notifications",
+		"Count of server-side win notifications sent to adapters labeled by adapter and whether they were delivered.",
+		[]string{adapterLabel, successLabel})
+
 	metrics.accountRequests = newCounter(cfg, metrics.Registry,
 		"account_requests",
 		"Count of total requests to Prebid Server labeled by account.",
@@ -331,10 +372,51 @@ func (m *Metrics) RecordAdapterBidReceived(labels pbsmetrics.AdapterLabels, bidT
 
 	m.adapterBids.With(prometheus.Labels{
 		adapterLabel:        string(labels.Adapter),
+		bidTypeLabel:        string(bidType),
 		markupDeliveryLabel: markupDelivery,
 	}).Inc()
 }
 
+func (m *Metrics) RecordAdapterBidsDropped(adapter openrtb_ext.BidderName, reason pbsmetrics.BidDropReason, count int) {
+	m.adapterBidsDropped.With(prometheus.Labels{
+		adapterLabel:    string(adapter),
+		dropReasonLabel: string(reason),
+	}).Add(float64(count))
+}
+
+func (m *Metrics) RecordAdapterCurrencySelection(adapter openrtb_ext.BidderName, selection pbsmetrics.CurrencySelection) {
+	m.adapterCurrencies.With(prometheus.Labels{
+		adapterLabel:  string(adapter),
+		currencyLabel: string(selection),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterCircuitBreakerTransition(adapter openrtb_ext.BidderName, state pbsmetrics.CircuitBreakerState) {
+	m.adapterBreaker.With(prometheus.Labels{
+		adapterLabel:      string(adapter),
+		breakerStateLabel: string(state),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterWinNotification(adapter openrtb_ext.BidderName, delivered bool) {
+	m.adapterWinNotices.With(prometheus.Labels{
+		adapterLabel: string(adapter),
+		successLabel: strconv.FormatBool(delivered),
+	}).Inc()
+}
+
+func (m *Metrics) RecordAdapterGeneratedBidIDs(adapter openrtb_ext.BidderName, count int) {
+	m.adapterGeneratedIDs.With(prometheus.Labels{
+		adapterLabel: string(adapter),
+	}).Add(float64(count))
+}
+
+func (m *Metrics) RecordAdapterMarkupSize(adapter openrtb_ext.BidderName, bytes int) {
+	m.adapterMarkupSize.With(prometheus.Labels{
+		adapterLabel: string(adapter),
+	}).Observe(float64(bytes))
+}
+
 func (m *Metrics) RecordAdapterPrice(labels pbsmetrics.AdapterLabels, cpm float64) {
 	m.adapterPrices.With(prometheus.Labels{
 		adapterLabel: string(labels.Adapter),
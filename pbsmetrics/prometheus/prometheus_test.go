@@ -449,17 +449,123 @@ func TestAdapterBidReceivedMetric(t *testing.T) {
 			test.expectedAdmCount,
 			prometheus.Labels{
 				adapterLabel:        adapterName,
+				bidTypeLabel:        string(openrtb_ext.BidTypeBanner),
 				markupDeliveryLabel: markupDeliveryAdm,
 			})
 		assertCounterVecValue(t, test.description, "adapterBids[nurl]", m.adapterBids,
 			test.expectedNurlCount,
 			prometheus.Labels{
 				adapterLabel:        adapterName,
+				bidTypeLabel:        string(openrtb_ext.BidTypeBanner),
 				markupDeliveryLabel: markupDeliveryNurl,
 			})
 	}
 }
 
+func TestAdapterBidsDroppedMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterBidsDropped(openrtb_ext.BidderName(adapterName), pbsmetrics.BidDropReasonCurrencyConversion, 2)
+	m.RecordAdapterBidsDropped(openrtb_ext.BidderName(adapterName), pbsmetrics.BidDropReasonCurrencyConversion, 1)
+
+	assertCounterVecValue(t, "", "adapterBidsDropped[currency_conversion]", m.adapterBidsDropped,
+		float64(3),
+		prometheus.Labels{
+			adapterLabel:    adapterName,
+			dropReasonLabel: string(pbsmetrics.BidDropReasonCurrencyConversion),
+		})
+	assertCounterVecValue(t, "", "adapterBidsDropped[bid_limit]", m.adapterBidsDropped,
+		float64(0),
+		prometheus.Labels{
+			adapterLabel:    adapterName,
+			dropReasonLabel: string(pbsmetrics.BidDropReasonBidLimit),
+		})
+}
+
+func TestAdapterCurrencySelectionMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterCurrencySelection(openrtb_ext.BidderName(adapterName), pbsmetrics.CurrencySelectionOther)
+
+	assertCounterVecValue(t, "", "adapterCurrencies[other]", m.adapterCurrencies,
+		float64(1),
+		prometheus.Labels{
+			adapterLabel:  adapterName,
+			currencyLabel: string(pbsmetrics.CurrencySelectionOther),
+		})
+	assertCounterVecValue(t, "", "adapterCurrencies[first]", m.adapterCurrencies,
+		float64(0),
+		prometheus.Labels{
+			adapterLabel:  adapterName,
+			currencyLabel: string(pbsmetrics.CurrencySelectionFirst),
+		})
+}
+
+func TestAdapterCircuitBreakerMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterCircuitBreakerTransition(openrtb_ext.BidderName(adapterName), pbsmetrics.CircuitBreakerOpen)
+
+	assertCounterVecValue(t, "", "adapterBreaker[open]", m.adapterBreaker,
+		float64(1),
+		prometheus.Labels{
+			adapterLabel:      adapterName,
+			breakerStateLabel: string(pbsmetrics.CircuitBreakerOpen),
+		})
+	assertCounterVecValue(t, "", "adapterBreaker[closed]", m.adapterBreaker,
+		float64(0),
+		prometheus.Labels{
+			adapterLabel:      adapterName,
+			breakerStateLabel: string(pbsmetrics.CircuitBreakerClosed),
+		})
+}
+
+func TestAdapterWinNotificationMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterWinNotification(openrtb_ext.BidderName(adapterName), false)
+
+	assertCounterVecValue(t, "", "adapterWinNotices[false]", m.adapterWinNotices,
+		float64(1),
+		prometheus.Labels{
+			adapterLabel: adapterName,
+			successLabel: "false",
+		})
+	assertCounterVecValue(t, "", "adapterWinNotices[true]", m.adapterWinNotices,
+		float64(0),
+		prometheus.Labels{
+			adapterLabel: adapterName,
+			successLabel: "true",
+		})
+}
+
+func TestAdapterGeneratedBidIDsMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterGeneratedBidIDs(openrtb_ext.BidderName(adapterName), 3)
+
+	assertCounterVecValue(t, "", "adapterGeneratedIDs", m.adapterGeneratedIDs,
+		float64(3),
+		prometheus.Labels{
+			adapterLabel: adapterName,
+		})
+}
+
+func TestAdapterMarkupSizeMetric(t *testing.T) {
+	m := createMetricsForTesting()
+	adapterName := "anyName"
+
+	m.RecordAdapterMarkupSize(openrtb_ext.BidderName(adapterName), 2048)
+
+	result := getHistogramFromHistogramVec(m.adapterMarkupSize, adapterLabel, adapterName)
+	assertHistogram(t, "adapterMarkupSize", result, uint64(1), float64(2048))
+}
+
 func TestRecordAdapterPriceMetric(t *testing.T) {
 	m := createMetricsForTesting()
 	adapterName := "anyName"
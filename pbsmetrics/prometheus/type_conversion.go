@@ -34,6 +34,33 @@ func adapterErrorsAsString() []string {
 	return valuesAsString
 }
 
+func bidDropReasonsAsString() []string {
+	values := pbsmetrics.BidDropReasons()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
+func currencySelectionsAsString() []string {
+	values := pbsmetrics.CurrencySelections()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
+func bidTypesAsString() []string {
+	values := openrtb_ext.BidTypes()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
 func boolValuesAsString() []string {
 	return []string{
 		strconv.FormatBool(true),
@@ -41,6 +68,15 @@ func boolValuesAsString() []string {
 	}
 }
 
+func circuitBreakerStatesAsString() []string {
+	values := pbsmetrics.CircuitBreakerStates()
+	valuesAsString := make([]string, len(values))
+	for i, v := range values {
+		valuesAsString[i] = string(v)
+	}
+	return valuesAsString
+}
+
 func cookieTypesAsString() []string {
 	values := pbsmetrics.CookieTypes()
 	valuesAsString := make([]string, len(values))
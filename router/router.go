@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -192,6 +193,11 @@ func New(cfg *config.Configuration, rateConvertor *currencies.RateConverter) (r
 			MaxIdleConnsPerHost: cfg.Client.MaxIdleConnsPerHost,
 			IdleConnTimeout:     time.Duration(cfg.Client.IdleConnTimeout) * time.Second,
 			TLSClientConfig:     &tls.Config{RootCAs: certPool},
+			DialContext: (&net.Dialer{
+				Timeout: time.Duration(cfg.Client.ConnectTimeoutMs) * time.Millisecond,
+			}).DialContext,
+			TLSHandshakeTimeout:   time.Duration(cfg.Client.TLSHandshakeTimeoutMs) * time.Millisecond,
+			ResponseHeaderTimeout: time.Duration(cfg.Client.ResponseHeaderTimeoutMs) * time.Millisecond,
 		},
 	}
 
@@ -200,6 +206,11 @@ func New(cfg *config.Configuration, rateConvertor *currencies.RateConverter) (r
 			MaxIdleConns:        cfg.CacheClient.MaxIdleConns,
 			MaxIdleConnsPerHost: cfg.CacheClient.MaxIdleConnsPerHost,
 			IdleConnTimeout:     time.Duration(cfg.CacheClient.IdleConnTimeout) * time.Second,
+			DialContext: (&net.Dialer{
+				Timeout: time.Duration(cfg.CacheClient.ConnectTimeoutMs) * time.Millisecond,
+			}).DialContext,
+			TLSHandshakeTimeout:   time.Duration(cfg.CacheClient.TLSHandshakeTimeoutMs) * time.Millisecond,
+			ResponseHeaderTimeout: time.Duration(cfg.CacheClient.ResponseHeaderTimeoutMs) * time.Millisecond,
 		},
 	}
 